@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// SwitchAccountRequest defines the body for switching the account in use.
+type SwitchAccountRequest struct {
+	// AccountName The name of the account to switch to.
+	AccountName string `json:"account_name"`
+}
+
+// NewSwitchAccountRequest generates requests for switching the account that
+// the current token is issued for.
+func NewSwitchAccountRequest(server string, body SwitchAccountRequest) (*http.Request, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse("./api/switch-account")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// Accounts returns the accounts available to the logged in user, along
+// with the name of the account currently in use. The client must have been
+// created by NewLandscapeAPIClient with a LoginResponseProvider.
+func (c *ClientWithResponses) Accounts(ctx context.Context) ([]LoginAccount, string, error) {
+	s, err := sessionOf(c)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := s.Token(ctx); err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accounts == nil {
+		return nil, "", fmt.Errorf("login provider doesn't report accounts")
+	}
+
+	return slices.Clone(s.accounts), s.currentAccount, nil
+}
+
+// SwitchAccount makes account the account used by subsequent requests,
+// without logging in again. The account is kept across token renewals.
+func (c *ClientWithResponses) SwitchAccount(ctx context.Context, account string) error {
+	s, err := sessionOf(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Token(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accounts != nil && !slices.ContainsFunc(s.accounts, func(a LoginAccount) bool { return a.Name == account }) {
+		return fmt.Errorf("account %q is not available to this user", account)
+	}
+
+	if account != s.currentAccount {
+		if err := s.switchAccountLocked(ctx, account); err != nil {
+			return err
+		}
+	}
+	s.account = account
+
+	return nil
+}
+
+// sessionOf returns the session behind a client created by
+// NewLandscapeAPIClient.
+func sessionOf(c *ClientWithResponses) (*session, error) {
+	if raw, ok := c.ClientInterface.(*Client); ok {
+		if d, ok := raw.Client.(*reauthDoer); ok {
+			return d.session, nil
+		}
+	}
+
+	return nil, fmt.Errorf("client has no login session; create it with NewLandscapeAPIClient")
+}
+
+func (s *session) switchAccountLocked(ctx context.Context, account string) error {
+	c, ok := s.loginClient.ClientInterface.(*Client)
+	if !ok {
+		return fmt.Errorf("login client doesn't support switching accounts")
+	}
+
+	req, err := NewSwitchAccountRequest(c.Server, SwitchAccountRequest{AccountName: account})
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, nil); err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("switch account request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("switch account failed: %w", NewAPIError(resp, body))
+	}
+
+	var res LoginResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("failed to decode switch account response: %w", err)
+	}
+
+	s.setTokenLocked(res.Token, s.now())
+	s.currentAccount = account
+	if res.CurrentAccount != "" {
+		s.currentAccount = res.CurrentAccount
+	}
+	if res.Accounts != nil {
+		s.accounts = res.Accounts
+	}
+
+	return nil
+}
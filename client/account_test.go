@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccounts(t *testing.T) {
+	accounts := []LoginAccount{
+		{Name: "onward", Title: "Onward, Inc.", Default: true},
+		{Name: "upward", Title: "Upward, Ltd."},
+	}
+
+	var loginAccounts []string
+	switches := 0
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		var req LoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode login request: %v", err)
+		}
+
+		current := "onward"
+		if req.Account != nil {
+			current = *req.Account
+			loginAccounts = append(loginAccounts, current)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(LoginResponse{
+			Email:          "jan@example.com",
+			Token:          "token-" + current,
+			Accounts:       accounts,
+			CurrentAccount: current,
+		}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/switch-account", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("expected POST, got %s", r.Method)
+		}
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req SwitchAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode switch account request: %v", err)
+		}
+		switches++
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(LoginResponse{
+			Email:          "jan@example.com",
+			Token:          "token-" + req.AccountName,
+			Accounts:       accounts,
+			CurrentAccount: req.AccountName,
+		}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{"authorization": r.Header.Get("Authorization")}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Run("account sent at login", func(t *testing.T) {
+		account := "upward"
+		api, err := NewLandscapeAPIClient(server.URL, NewEmailPasswordProvider("jan@example.com", "pw", &account))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		if len(loginAccounts) != 1 || loginAccounts[0] != "upward" {
+			t.Fatalf("expected account to be sent at login, got %v", loginAccounts)
+		}
+
+		got, current, err := api.Accounts(context.Background())
+		if err != nil {
+			t.Fatalf("Accounts failed: %v", err)
+		}
+		if current != "upward" || len(got) != 2 {
+			t.Fatalf("unexpected accounts %+v (current %q)", got, current)
+		}
+	})
+
+	t.Run("switch account", func(t *testing.T) {
+		api, err := NewLandscapeAPIClient(server.URL, NewEmailPasswordProvider("jan@example.com", "pw", nil))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		if err := api.SwitchAccount(context.Background(), "upward"); err != nil {
+			t.Fatalf("SwitchAccount failed: %v", err)
+		}
+		if switches != 1 {
+			t.Fatalf("expected 1 switch, got %d", switches)
+		}
+
+		_, current, err := api.Accounts(context.Background())
+		if err != nil {
+			t.Fatalf("Accounts failed: %v", err)
+		}
+		if current != "upward" {
+			t.Fatalf("expected current account upward, got %q", current)
+		}
+
+		resp, err := api.GetScript(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScript failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var payload map[string]string
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if payload["authorization"] != "Bearer token-upward" {
+			t.Fatalf("expected switched token to be used, got %q", payload["authorization"])
+		}
+	})
+
+	t.Run("switch to unknown account", func(t *testing.T) {
+		api, err := NewLandscapeAPIClient(server.URL, NewEmailPasswordProvider("jan@example.com", "pw", nil))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		if err := api.SwitchAccount(context.Background(), "sideways"); err == nil {
+			t.Fatal("expected error switching to unknown account")
+		}
+	})
+
+	t.Run("client without session", func(t *testing.T) {
+		api, err := NewClientWithResponses(server.URL)
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		if _, _, err := api.Accounts(context.Background()); err == nil {
+			t.Fatal("expected error listing accounts without a session")
+		}
+	})
+}
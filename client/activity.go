@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ActivityStatus is the state of an activity, such as a script run on a
+// computer.
+type ActivityStatus string
+
+const (
+	ActivityUndelivered ActivityStatus = "undelivered"
+	ActivityDelivered   ActivityStatus = "delivered"
+	ActivityUnapproved  ActivityStatus = "unapproved"
+	ActivitySucceeded   ActivityStatus = "succeeded"
+	ActivityFailed      ActivityStatus = "failed"
+	ActivityCanceled    ActivityStatus = "canceled"
+)
+
+// Done reports whether an activity with this status has finished and won't
+// change again.
+func (s ActivityStatus) Done() bool {
+	switch s {
+	case ActivitySucceeded, ActivityFailed, ActivityCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Activity is a request sent to computers. Running a script creates a
+// parent activity, with a child activity for each computer it runs on.
+type Activity struct {
+	Id             int            `json:"id"`
+	ParentId       *int           `json:"parent_id,omitempty"`
+	ComputerId     *int           `json:"computer_id,omitempty"`
+	Type           string         `json:"type,omitempty"`
+	Summary        string         `json:"summary,omitempty"`
+	ActivityStatus ActivityStatus `json:"activity_status"`
+	// ResultCode is a script's exit code, once it has finished.
+	ResultCode *int `json:"result_code,omitempty"`
+	// ResultText is a script's output, once it has finished.
+	ResultText     *string `json:"result_text,omitempty"`
+	CreationTime   string  `json:"creation_time,omitempty"`
+	CompletionTime *string `json:"completion_time,omitempty"`
+}
+
+// ActivityList is a page of activities.
+type ActivityList struct {
+	Count   int        `json:"count"`
+	Next    *string    `json:"next,omitempty"`
+	Results []Activity `json:"results"`
+}
+
+// AsActivity returns the union data inside the LegacyActionResponse as an
+// Activity.
+func (t LegacyActionResponse) AsActivity() (*Activity, error) {
+	var activity Activity
+	if err := json.Unmarshal(t.union, &activity); err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// AsActivities returns the union data inside the LegacyActionResponse as a
+// list of activities.
+func (t LegacyActionResponse) AsActivities() ([]Activity, error) {
+	var activities []Activity
+	err := json.Unmarshal(t.union, &activities)
+	return activities, err
+}
+
+// activityPageSize is the number of activities fetched per request.
+const activityPageSize = 100
+
+// ActivityService reads and follows the activities created by requests
+// such as running a script.
+type ActivityService struct {
+	client *ClientWithResponses
+}
+
+// Activities returns an ActivityService that sends requests through c.
+func (c *ClientWithResponses) Activities() *ActivityService {
+	return &ActivityService{client: c}
+}
+
+// List returns every activity matching query, such as "parent-id:12",
+// fetching as many pages as needed.
+func (s *ActivityService) List(ctx context.Context, query string) ([]Activity, error) {
+	var activities []Activity
+
+	for offset := 0; ; offset += activityPageSize {
+		res, err := ResponseValue[LegacyActionResponse](
+			s.client.InvokeLegacyActionWithResponse(ctx, LegacyActionParams("GetActivities"), EncodeQueryRequestEditor(url.Values{
+				"query":  []string{query},
+				"limit":  []string{strconv.Itoa(activityPageSize)},
+				"offset": []string{strconv.Itoa(offset)},
+			})),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := res.AsActivities()
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, page...)
+
+		if len(page) < activityPageSize {
+			return activities, nil
+		}
+	}
+}
+
+// Get returns the activity with the given ID.
+func (s *ActivityService) Get(ctx context.Context, id int) (*Activity, error) {
+	activities, err := s.List(ctx, "id:"+strconv.Itoa(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return nil, fmt.Errorf("activity %d not found", id)
+	}
+
+	return &activities[0], nil
+}
+
+// Children returns the child activities of the activity with the given
+// ID, one for each computer it was sent to.
+func (s *ActivityService) Children(ctx context.Context, id int) ([]Activity, error) {
+	return s.List(ctx, "parent-id:"+strconv.Itoa(id))
+}
+
+// Wait polls the activity with the given ID every interval until all of
+// its child activities are done, and returns them. It stops early with
+// ctx's error if ctx is done first.
+func (s *ActivityService) Wait(ctx context.Context, id int, interval time.Duration) ([]Activity, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		children, done, err := s.poll(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return children, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll returns the child activities of the activity with the given ID and
+// whether they are all done. An activity without children, such as one
+// whose query matched no computers, is done when it is.
+func (s *ActivityService) poll(ctx context.Context, id int) ([]Activity, bool, error) {
+	children, err := s.Children(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(children) == 0 {
+		activity, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		return children, activity.ActivityStatus.Done(), nil
+	}
+
+	for _, child := range children {
+		if !child.ActivityStatus.Done() {
+			return children, false, nil
+		}
+	}
+
+	return children, true, nil
+}
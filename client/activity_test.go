@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestActivities(t *testing.T) {
+	var (
+		executed url.Values
+		polls    int
+	)
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var resp any
+		switch query.Get("action") {
+		case "ExecuteScript":
+			executed = query
+			resp = map[string]any{"id": 100, "type": "ActivityGroup", "activity_status": "undelivered"}
+		case "GetActivities":
+			switch query.Get("query") {
+			case "parent-id:100":
+				polls++
+				status := "delivered"
+				if polls > 1 {
+					status = "failed"
+				}
+				resp = []map[string]any{
+					{"id": 101, "parent_id": 100, "computer_id": 1, "activity_status": "succeeded", "result_code": 0, "result_text": "ok"},
+					{"id": 102, "parent_id": 100, "computer_id": 2, "activity_status": status, "result_code": 1},
+				}
+			case "parent-id:200":
+				resp = []map[string]any{}
+			case "id:200":
+				resp = []map[string]any{{"id": 200, "activity_status": "succeeded"}}
+			default:
+				resp = []map[string]any{}
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			resp = map[string]any{"message": "unknown action"}
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	t.Run("execute", func(t *testing.T) {
+		activity, err := api.Scripts().Execute(context.Background(), 42, ExecuteScriptParams{
+			Query:     "tag:servers",
+			Username:  "root",
+			TimeLimit: 60,
+		})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		if activity.Id != 100 || activity.ActivityStatus != ActivityUndelivered {
+			t.Fatalf("unexpected activity %+v", activity)
+		}
+		if executed.Get("script_id") != "42" || executed.Get("query") != "tag:servers" ||
+			executed.Get("username") != "root" || executed.Get("time_limit") != "60" {
+			t.Fatalf("unexpected query %v", executed)
+		}
+	})
+
+	t.Run("execute requires a query", func(t *testing.T) {
+		if _, err := api.Scripts().Execute(context.Background(), 42, ExecuteScriptParams{}); err == nil {
+			t.Fatal("expected an error without a query")
+		}
+	})
+
+	t.Run("wait for children", func(t *testing.T) {
+		polls = 0
+
+		children, err := api.Activities().Wait(context.Background(), 100, time.Millisecond)
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+
+		if polls != 2 {
+			t.Fatalf("expected 2 polls, got %d", polls)
+		}
+		if len(children) != 2 || children[1].ActivityStatus != ActivityFailed || *children[1].ResultCode != 1 {
+			t.Fatalf("unexpected children %+v", children)
+		}
+	})
+
+	t.Run("wait without children", func(t *testing.T) {
+		children, err := api.Activities().Wait(context.Background(), 200, time.Millisecond)
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		if len(children) != 0 {
+			t.Fatalf("expected no children, got %+v", children)
+		}
+	})
+
+	t.Run("wait stops with the context", func(t *testing.T) {
+		polls = -100
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := api.Activities().Wait(ctx, 100, time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the deadline to be exceeded, got %v", err)
+		}
+	})
+}
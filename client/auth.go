@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshLeeway is how long before a token's expiry the session
+// logs in again, so that requests in flight don't race the expiry.
+const tokenRefreshLeeway = time.Minute
+
+// untimedTokenLifetime is how long a token without an exp claim is used
+// before logging in again. There's no telling when the server stops
+// accepting such a token, so it's kept short; a token rejected sooner is
+// still renewed when the server responds with 401.
+const untimedTokenLifetime = 15 * time.Minute
+
+// session owns the JWT used by an authenticated client. The token is
+// renewed through the LoginProvider shortly before it expires, or when
+// the server rejects it. Concurrent callers share a single renewal.
+type session struct {
+	provider    LoginProvider
+	loginClient *ClientWithResponses
+	now         func() time.Time
+
+	mu             sync.Mutex
+	token          string
+	issued         time.Time
+	expiry         time.Time
+	accounts       []LoginAccount
+	currentAccount string
+	// account is the account selected with SwitchAccount, which is
+	// switched to again after every login.
+	account string
+
+	// cache, if set, keeps the token under cacheKey between sessions.
+	// The key is built for cacheAccount, so tokens for other accounts
+	// selected with SwitchAccount aren't stored.
+	cache        TokenCache
+	cacheKey     string
+	cacheAccount string
+	loaded       bool
+}
+
+func newSession(provider LoginProvider, loginClient *ClientWithResponses) *session {
+	return &session{
+		provider:    provider,
+		loginClient: loginClient,
+		now:         time.Now,
+	}
+}
+
+// Token returns the current token, logging in again first if there is
+// no token yet or it is about to expire.
+func (s *session) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" && !s.loaded {
+		s.loadCachedLocked()
+	}
+
+	if s.token != "" && !s.expiringLocked() {
+		return s.token, nil
+	}
+
+	return s.loginLocked(ctx)
+}
+
+// renew replaces a token that the server rejected. If another caller
+// has already replaced it, the newer token is returned without logging
+// in again.
+func (s *session) renew(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.token != rejected {
+		return s.token, nil
+	}
+
+	if s.cache != nil {
+		// A failure here only means a later session logs in again.
+		_ = s.cache.Delete(s.cacheKey)
+	}
+
+	return s.loginLocked(ctx)
+}
+
+// loadCachedLocked takes the session's token from the cache. A token that
+// can't be loaded is ignored, so the session logs in instead.
+func (s *session) loadCachedLocked() {
+	s.loaded = true
+	if s.cache == nil {
+		return
+	}
+
+	cached, err := s.cache.Load(s.cacheKey)
+	if err != nil || cached == nil || cached.Token == "" {
+		return
+	}
+
+	s.setTokenLocked(cached.Token, cached.IssuedAt)
+	s.accounts = cached.Accounts
+	s.currentAccount = cached.CurrentAccount
+}
+
+// storeCachedLocked saves the session's token in the cache. Failing to
+// save it doesn't fail the login.
+func (s *session) storeCachedLocked() {
+	if s.cache == nil || s.account != s.cacheAccount {
+		return
+	}
+
+	_ = s.cache.Store(s.cacheKey, &CachedToken{
+		Token:          s.token,
+		IssuedAt:       s.issued,
+		CurrentAccount: s.currentAccount,
+		Accounts:       s.accounts,
+	})
+}
+
+func (s *session) expiringLocked() bool {
+	return !s.now().Add(tokenRefreshLeeway).Before(s.expiry)
+}
+
+func (s *session) loginLocked(ctx context.Context) (string, error) {
+	if p, ok := s.provider.(LoginResponseProvider); ok {
+		res, err := p.LoginWithResponse(ctx, s.loginClient)
+		if err != nil {
+			return "", fmt.Errorf("login failed: %w", err)
+		}
+
+		s.setTokenLocked(res.Token, s.now())
+		s.accounts = res.Accounts
+		s.currentAccount = res.CurrentAccount
+	} else {
+		token, err := s.provider.Login(ctx, s.loginClient)
+		if err != nil {
+			return "", fmt.Errorf("login failed: %w", err)
+		}
+
+		s.setTokenLocked(token, s.now())
+	}
+
+	if s.account != "" && s.account != s.currentAccount {
+		if err := s.switchAccountLocked(ctx, s.account); err != nil {
+			return "", err
+		}
+	}
+
+	s.storeCachedLocked()
+
+	return s.token, nil
+}
+
+// setTokenLocked makes token the session's token. Tokens without an exp
+// claim expire untimedTokenLifetime after issued.
+func (s *session) setTokenLocked(token string, issued time.Time) {
+	s.token = token
+	s.issued = issued
+	s.expiry = tokenExpiry(token)
+	if s.expiry.IsZero() {
+		s.expiry = issued.Add(untimedTokenLifetime)
+	}
+}
+
+// editRequest is a RequestEditorFn that sets the session's token as the
+// request's Bearer token.
+func (s *session) editRequest(ctx context.Context, req *http.Request) error {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// tokenExpiry returns the time in the JWT's exp claim, or the zero time
+// if the token has no exp claim or can't be decoded.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(*claims.Exp), 0)
+}
+
+// reauthDoer is an HttpRequestDoer that renews the session's token and
+// replays the request once when the server responds with 401.
+type reauthDoer struct {
+	session *session
+	doer    HttpRequestDoer
+}
+
+// Do implements HttpRequestDoer for reauthDoer.
+func (d *reauthDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	retry, err := rewindRequest(req)
+	if err != nil {
+		// The body can't be sent again, so let the caller see the 401.
+		return resp, nil
+	}
+
+	rejected := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	token, err := d.session.renew(req.Context(), rejected)
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	retry.Header.Set("Authorization", "Bearer "+token)
+	return d.doer.Do(retry)
+}
+
+// rewindRequest returns a copy of req that can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body can't be replayed")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone.Body = body
+
+	return clone, nil
+}
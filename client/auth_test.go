@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testJWT(t *testing.T, n int, exp time.Time) string {
+	t.Helper()
+
+	claims, err := json.Marshal(map[string]any{"sub": n, "exp": exp.Unix()})
+	if err != nil {
+		t.Fatalf("failed to encode claims: %v", err)
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"HS256"}`)) + "." + enc.EncodeToString(claims) + ".sig"
+}
+
+// newAuthTestServer serves the access key login endpoint, handing out a new
+// token on every login, and a script endpoint that rejects tokens listed in
+// rejected.
+func newAuthTestServer(t *testing.T, expiry func(login int) time.Time, rejected func(token string) bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var logins atomic.Int32
+	tokens := sync.Map{}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/login/access-key", func(w http.ResponseWriter, r *http.Request) {
+		n := int(logins.Add(1))
+		token := testJWT(t, n, expiry(n))
+		tokens.Store(token, n)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"token": token}); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/1", func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		token := auth[len("Bearer "):]
+		if _, ok := tokens.Load(token); !ok || rejected(token) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "token expired"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 1, "title": "diagnostic script", "status": "V1"}`)
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server, &logins
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(1893456000, 0)
+
+	if got := tokenExpiry(testJWT(t, 1, exp)); !got.Equal(exp) {
+		t.Fatalf("expected expiry %v, got %v", exp, got)
+	}
+
+	if got := tokenExpiry("not-a-jwt"); !got.IsZero() {
+		t.Fatalf("expected zero expiry for opaque token, got %v", got)
+	}
+}
+
+// countingProvider is a LoginProvider that hands out a new opaque token,
+// without an exp claim, on every login.
+type countingProvider struct {
+	logins int
+}
+
+func (p *countingProvider) Login(context.Context, *ClientWithResponses) (string, error) {
+	p.logins++
+	return fmt.Sprintf("opaque-%d", p.logins), nil
+}
+
+func TestSessionExpiresUntimedTokens(t *testing.T) {
+	now := time.Now()
+	provider := &countingProvider{}
+	cache := NewFileTokenCache(filepath.Join(t.TempDir(), "tokens.json"))
+
+	newTestSession := func() *session {
+		s := newSession(provider, nil)
+		s.now = func() time.Time { return now }
+		s.cache = cache
+		s.cacheKey = "key"
+		return s
+	}
+
+	token := func(s *session, want string) {
+		t.Helper()
+
+		got, err := s.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected token %q, got %q", want, got)
+		}
+	}
+
+	s := newTestSession()
+	token(s, "opaque-1")
+
+	now = now.Add(untimedTokenLifetime / 2)
+	token(s, "opaque-1")
+	token(newTestSession(), "opaque-1")
+
+	now = now.Add(untimedTokenLifetime / 2)
+	token(s, "opaque-2")
+
+	// A cached token without an issue time, as stored by older versions,
+	// is treated as expired.
+	if err := cache.Store("key", &CachedToken{Token: "opaque-old"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	token(newTestSession(), "opaque-3")
+}
+
+func TestLandscapeAPIClientRenewsExpiringToken(t *testing.T) {
+	expiry := func(login int) time.Time {
+		if login == 1 {
+			return time.Now().Add(tokenRefreshLeeway / 2)
+		}
+		return time.Now().Add(time.Hour)
+	}
+	server, logins := newAuthTestServer(t, expiry, func(string) bool { return false })
+
+	api, err := NewLandscapeAPIClient(server.URL, NewAccessKeyProvider("ak", "sk"))
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	for range 2 {
+		resp, err := api.GetScriptWithResponse(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected HTTP 200 but received %d", resp.StatusCode())
+		}
+	}
+
+	if n := logins.Load(); n != 2 {
+		t.Fatalf("expected 2 logins, got %d", n)
+	}
+}
+
+func TestLandscapeAPIClientReplaysUnauthorized(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+	expiry := func(int) time.Time { return exp }
+
+	var firstToken atomic.Value
+	rejected := func(token string) bool {
+		first, _ := firstToken.Load().(string)
+		return token == first
+	}
+	server, logins := newAuthTestServer(t, expiry, rejected)
+
+	api, err := NewLandscapeAPIClient(server.URL, NewAccessKeyProvider("ak", "sk"))
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+	firstToken.Store(testJWT(t, 1, expiry(1)))
+
+	t.Run("single request", func(t *testing.T) {
+		resp, err := api.GetScriptWithResponse(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected HTTP 200 after re-login but received %d", resp.StatusCode())
+		}
+		if n := logins.Load(); n != 2 {
+			t.Fatalf("expected 2 logins, got %d", n)
+		}
+	})
+
+	t.Run("concurrent requests share renewal", func(t *testing.T) {
+		firstToken.Store(testJWT(t, 2, expiry(2)))
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				resp, err := api.GetScriptWithResponse(context.Background(), 1)
+				if err != nil {
+					t.Errorf("GetScriptWithResponse failed: %v", err)
+					return
+				}
+				if resp.StatusCode() != http.StatusOK {
+					t.Errorf("expected HTTP 200 after re-login but received %d", resp.StatusCode())
+				}
+			}()
+		}
+		wg.Wait()
+
+		if n := logins.Load(); n != 3 {
+			t.Fatalf("expected 3 logins, got %d", n)
+		}
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -444,7 +443,6 @@ func (t ScriptResult) AsV1Script() (V1Script, error) {
 
 // FromV1Script overwrites any union data inside the ScriptResult as the provided V1Script
 func (t *ScriptResult) FromV1Script(v V1Script) error {
-	v.Status = "V1Script"
 	b, err := json.Marshal(v)
 	t.union = b
 	return err
@@ -452,7 +450,6 @@ func (t *ScriptResult) FromV1Script(v V1Script) error {
 
 // MergeV1Script performs a merge with any union data inside the ScriptResult, using the provided V1Script
 func (t *ScriptResult) MergeV1Script(v V1Script) error {
-	v.Status = "V1Script"
 	b, err := json.Marshal(v)
 	if err != nil {
 		return err
@@ -472,7 +469,6 @@ func (t ScriptResult) AsV2Script() (V2Script, error) {
 
 // FromV2Script overwrites any union data inside the ScriptResult as the provided V2Script
 func (t *ScriptResult) FromV2Script(v V2Script) error {
-	v.Status = "V2Script"
 	b, err := json.Marshal(v)
 	t.union = b
 	return err
@@ -480,7 +476,6 @@ func (t *ScriptResult) FromV2Script(v V2Script) error {
 
 // MergeV2Script performs a merge with any union data inside the ScriptResult, using the provided V2Script
 func (t *ScriptResult) MergeV2Script(v V2Script) error {
-	v.Status = "V2Script"
 	b, err := json.Marshal(v)
 	if err != nil {
 		return err
@@ -491,29 +486,6 @@ func (t *ScriptResult) MergeV2Script(v V2Script) error {
 	return err
 }
 
-func (t ScriptResult) Discriminator() (string, error) {
-	var discriminator struct {
-		Discriminator string `json:"status"`
-	}
-	err := json.Unmarshal(t.union, &discriminator)
-	return discriminator.Discriminator, err
-}
-
-func (t ScriptResult) ValueByDiscriminator() (interface{}, error) {
-	discriminator, err := t.Discriminator()
-	if err != nil {
-		return nil, err
-	}
-	switch discriminator {
-	case "V1Script":
-		return t.AsV1Script()
-	case "V2Script":
-		return t.AsV2Script()
-	default:
-		return nil, errors.New("unknown discriminator value: " + discriminator)
-	}
-}
-
 func (t ScriptResult) MarshalJSON() ([]byte, error) {
 	b, err := t.union.MarshalJSON()
 	return b, err
@@ -18,6 +18,14 @@ type LoginProvider interface {
 	Login(ctx context.Context, client *ClientWithResponses) (string, error)
 }
 
+// LoginResponseProvider is a LoginProvider that can also return the full
+// LoginResponse, which lists the accounts available to the user. Clients
+// created with a LoginResponseProvider support Accounts and SwitchAccount.
+type LoginResponseProvider interface {
+	LoginProvider
+	LoginWithResponse(ctx context.Context, client *ClientWithResponses) (*LoginResponse, error)
+}
+
 // EmailPasswordProvider logs in with an email/password pair and optionally
 // specifies an account name.
 type EmailPasswordProvider struct {
@@ -36,21 +44,36 @@ func NewEmailPasswordProvider(email, password string, account *string) *EmailPas
 
 // Login implements LoginProvider for EmailPasswordProvider.
 func (p *EmailPasswordProvider) Login(ctx context.Context, c *ClientWithResponses) (string, error) {
+	res, err := p.LoginWithResponse(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Token, nil
+}
+
+// LoginWithResponse implements LoginResponseProvider for EmailPasswordProvider.
+func (p *EmailPasswordProvider) LoginWithResponse(ctx context.Context, c *ClientWithResponses) (*LoginResponse, error) {
+	if p.Email == "" || p.Password == "" {
+		return nil, fmt.Errorf("%w: email and password must both be provided", ErrMissingCredentials)
+	}
+
 	resp, err := c.LoginWithPasswordWithResponse(ctx, LoginWithPasswordJSONRequestBody{
+		Account:  p.Account,
 		Email:    openapi_types.Email(p.Email),
 		Password: p.Password,
 	})
 	if err != nil {
-		return "", fmt.Errorf("login with password request failed: %w", err)
+		return nil, fmt.Errorf("login with password request failed: %w", err)
 	}
 	if resp == nil {
-		return "", fmt.Errorf("nil response from login")
+		return nil, fmt.Errorf("nil response from login")
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("login failed with status: %d", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK || resp.JSON200 == nil {
+		return nil, NewAPIError(resp.HTTPResponse, resp.Body)
 	}
 
-	return resp.JSON200.Token, nil
+	return resp.JSON200, nil
 }
 
 // AccessKeyProvider logs in with an access key/secret key pair.
@@ -68,43 +91,79 @@ func NewAccessKeyProvider(accessKey, secretKey string) *AccessKeyProvider {
 
 // Login implements LoginProvider for AccessKeyProvider.
 func (p *AccessKeyProvider) Login(ctx context.Context, c *ClientWithResponses) (string, error) {
+	res, err := p.LoginWithResponse(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Token, nil
+}
+
+// LoginWithResponse implements LoginResponseProvider for AccessKeyProvider.
+func (p *AccessKeyProvider) LoginWithResponse(ctx context.Context, c *ClientWithResponses) (*LoginResponse, error) {
+	if p.AccessKey == "" || p.SecretKey == "" {
+		return nil, fmt.Errorf("%w: access key and secret key must both be provided", ErrMissingCredentials)
+	}
+
 	resp, err := c.LoginWithAccessKeyWithResponse(ctx, LoginWithAccessKeyJSONRequestBody{
 		AccessKey: p.AccessKey,
 		SecretKey: p.SecretKey,
 	})
 	if err != nil {
-		return "", fmt.Errorf("login with access key request failed: %w", err)
+		return nil, fmt.Errorf("login with access key request failed: %w", err)
 	}
 	if resp == nil {
-		return "", fmt.Errorf("nil response from login")
+		return nil, fmt.Errorf("nil response from login")
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("login failed with status: %d", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK || resp.JSON200 == nil {
+		return nil, NewAPIError(resp.HTTPResponse, resp.Body)
 	}
 
-	return resp.JSON200.Token, nil
+	return resp.JSON200, nil
 }
 
 // NewLandscapeAPIClient creates a new Landscape API client configured with authentication
 // provided by the given LoginProvider. The provider is used to obtain a JWT token which
 // is then applied to subsequent requests as a Bearer token.
+//
+// The token is renewed through the same provider shortly before its exp claim, and
+// a request rejected with 401 is replayed once after logging in again.
 func NewLandscapeAPIClient(baseURL string, loginProvider LoginProvider) (*ClientWithResponses, error) {
-	tempClient, err := NewClientWithResponses(baseURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp client: %w", err)
+	return NewLandscapeAPIClientWithContext(context.Background(), baseURL, loginProvider)
+}
+
+// NewLandscapeAPIClientWithContext is like NewLandscapeAPIClient, but logs in
+// with the given context and configures the HTTP transport with opts.
+func NewLandscapeAPIClientWithContext(ctx context.Context, baseURL string, loginProvider LoginProvider, opts ...Option) (*ClientWithResponses, error) {
+	var o options
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
 	}
 
-	token, err := loginProvider.Login(context.Background(), tempClient)
+	doer := o.httpDoer()
+
+	tempClient, err := NewClientWithResponses(baseURL, append(o.baseClientOptions(), WithHTTPClient(doer))...)
 	if err != nil {
-		return nil, fmt.Errorf("login failed: %w", err)
+		return nil, fmt.Errorf("failed to create temp client: %w", err)
 	}
 
-	authEditor := func(ctx context.Context, req *http.Request) error {
-		req.Header.Set("Authorization", "Bearer "+token)
-		return nil
+	s := newSession(loginProvider, tempClient)
+	s.cache = o.tokenCache
+	s.cacheKey = o.tokenCacheKey
+	s.cacheAccount = o.account
+	s.account = o.account
+	if _, err := s.Token(ctx); err != nil {
+		return nil, err
 	}
 
-	return NewClientWithResponses(baseURL, WithRequestEditorFn(authEditor))
+	clientOpts := append(o.baseClientOptions(),
+		WithHTTPClient(&reauthDoer{session: s, doer: doer}),
+		WithRequestEditorFn(s.editRequest),
+	)
+
+	return NewClientWithResponses(baseURL, clientOpts...)
 }
 
 // LegacyActionParams is a helper to call legacy API
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Sentinel errors matched by APIError with errors.Is, based on the
+// response's status code.
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrServer          = errors.New("server error")
+)
+
+// APIError is an error response from Landscape. It carries the details of
+// the request that failed and the server's Error payload, if any.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the code reported in the Error payload, if any.
+	Code int
+	// Message is the message reported in the Error payload, if any.
+	Message string
+	// Method and URL identify the request that failed.
+	Method string
+	URL    string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// NewAPIError builds an APIError from a response and its already read body.
+func NewAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{Body: body}
+
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		if resp.Request != nil {
+			e.Method = resp.Request.Method
+			if resp.Request.URL != nil {
+				e.URL = resp.Request.URL.Redacted()
+			}
+		}
+	}
+
+	var payload Error
+	if json.Unmarshal(body, &payload) == nil {
+		if payload.Code != nil {
+			e.Code = *payload.Code
+		}
+		if payload.Message != nil {
+			e.Message = *payload.Message
+		}
+	}
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+
+	if e.Method != "" || e.URL != "" {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.URL)
+	}
+
+	fmt.Fprintf(&b, "%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+
+	return b.String()
+}
+
+// Is reports whether target is the sentinel error for e's status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// IsNotFound reports whether err is an APIError for a 404 response.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError for a 401 response.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError for a 403 response.
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsBadRequest reports whether err is an APIError for a 400 response.
+func IsBadRequest(err error) bool {
+	return errors.Is(err, ErrBadRequest)
+}
+
+// Response is implemented by the *...Response types returned by
+// ClientWithResponses, such as *GetScriptResponse.
+type Response interface {
+	Status() string
+	StatusCode() int
+}
+
+// CheckResponse returns err if the request failed, or an *APIError if the
+// response doesn't have a 2xx status. It suits operations without a
+// payload, such as ArchiveScriptWithResponse:
+//
+//	err := client.CheckResponse(api.ArchiveScriptWithResponse(ctx, id))
+func CheckResponse(resp Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if isNil(resp) {
+		return fmt.Errorf("nil response")
+	}
+
+	if status := resp.StatusCode(); status < 200 || status > 299 {
+		httpResp, body := responseFields(resp)
+		return NewAPIError(httpResp, body)
+	}
+
+	return nil
+}
+
+// ResponseValue returns the JSON200 payload of resp, or an *APIError if the
+// response doesn't have a 2xx status:
+//
+//	script, err := client.ResponseValue[client.ScriptResult](api.GetScriptWithResponse(ctx, id))
+func ResponseValue[T any, R Response](resp R, err error) (T, error) {
+	var zero T
+
+	if err := CheckResponse(resp, err); err != nil {
+		return zero, err
+	}
+
+	var field reflect.Value
+	if v := responseStruct(resp); v.IsValid() {
+		field = v.FieldByName("JSON200")
+	}
+	if !field.IsValid() || field.Kind() != reflect.Pointer || field.IsNil() {
+		httpResp, body := responseFields(resp)
+		apiErr := NewAPIError(httpResp, body)
+		apiErr.Message = "response has no JSON payload"
+		return zero, apiErr
+	}
+
+	value, ok := field.Elem().Interface().(T)
+	if !ok {
+		return zero, fmt.Errorf("response payload is %s, not %T", field.Elem().Type(), zero)
+	}
+
+	return value, nil
+}
+
+// responseFields returns the HTTPResponse and Body fields that every
+// generated *...Response type has.
+func responseFields(resp Response) (*http.Response, []byte) {
+	v := responseStruct(resp)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	var httpResp *http.Response
+	if f := v.FieldByName("HTTPResponse"); f.IsValid() {
+		httpResp, _ = f.Interface().(*http.Response)
+	}
+
+	var body []byte
+	if f := v.FieldByName("Body"); f.IsValid() {
+		body, _ = f.Interface().([]byte)
+	}
+
+	return httpResp, body
+}
+
+func responseStruct(resp Response) reflect.Value {
+	v := reflect.ValueOf(resp)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	return v
+}
+
+func isNil(resp Response) bool {
+	if resp == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(resp)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/scripts/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(V1Script{Id: 1, Title: "diagnostic script", Status: V1}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/99", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		if err := json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "script 99 not found"}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/1:archive", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler.HandleFunc("/api/scripts/99:archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream unavailable"))
+	})
+	handler.HandleFunc("/api/login/access-key", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		if err := json.NewEncoder(w).Encode(map[string]any{"message": "invalid access key"}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	t.Run("value", func(t *testing.T) {
+		script, err := ResponseValue[ScriptResult](api.GetScriptWithResponse(context.Background(), 1))
+		if err != nil {
+			t.Fatalf("ResponseValue failed: %v", err)
+		}
+
+		v1, err := script.AsV1Script()
+		if err != nil || v1.Id != 1 {
+			t.Fatalf("unexpected script %+v (%v)", v1, err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := ResponseValue[ScriptResult](api.GetScriptWithResponse(context.Background(), 99))
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		if IsUnauthorized(err) {
+			t.Fatal("not found error matched ErrUnauthorized")
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
+		}
+		if apiErr.Code != 404 || apiErr.Message != "script 99 not found" || apiErr.Method != http.MethodGet {
+			t.Fatalf("unexpected APIError: %+v", apiErr)
+		}
+		if !strings.HasSuffix(apiErr.URL, "/api/scripts/99") {
+			t.Fatalf("unexpected URL %q", apiErr.URL)
+		}
+		if !strings.Contains(err.Error(), "script 99 not found") {
+			t.Fatalf("expected server message in error, got %q", err)
+		}
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		if err := CheckResponse(api.ArchiveScriptWithResponse(context.Background(), 1)); err != nil {
+			t.Fatalf("CheckResponse failed: %v", err)
+		}
+	})
+
+	t.Run("non-JSON error body", func(t *testing.T) {
+		err := CheckResponse(api.ArchiveScriptWithResponse(context.Background(), 99))
+		if !errors.Is(err, ErrServer) {
+			t.Fatalf("expected server error, got %v", err)
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || string(apiErr.Body) != "upstream unavailable" {
+			t.Fatalf("expected raw body to be kept, got %+v", apiErr)
+		}
+	})
+
+	t.Run("login error", func(t *testing.T) {
+		_, err := NewLandscapeAPIClient(server.URL, NewAccessKeyProvider("ak", "sk"))
+		if !IsUnauthorized(err) {
+			t.Fatalf("expected unauthorized error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "invalid access key") {
+			t.Fatalf("expected server message in error, got %q", err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// EncodeLegacyParams flattens params into the query parameters expected by
+// legacy API actions. Lists are sent as name.1, name.2, and so on, and maps
+// as name.key, nesting as needed:
+//
+//	EncodeLegacyParams(map[string]any{"computer_ids": []int{1, 2}, "tags": map[string]string{"env": "prod"}})
+//	// computer_ids.1=1&computer_ids.2=2&tags.env=prod
+//
+// Byte slices are base64 encoded, and nil values are left out.
+func EncodeLegacyParams(params map[string]any) (url.Values, error) {
+	values := url.Values{}
+
+	for name, v := range params {
+		if err := encodeLegacyParam(values, name, reflect.ValueOf(v)); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func encodeLegacyParam(values url.Values, name string, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.String:
+		values.Set(name, v.String())
+	case reflect.Bool:
+		values.Set(name, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Set(name, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Set(name, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Set(name, strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()))
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			values.Set(name, base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		for i := range v.Len() {
+			if err := encodeLegacyParam(values, name+"."+strconv.Itoa(i+1), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("parameter %s: map keys must be strings, not %s", name, v.Type().Key())
+		}
+		for _, key := range v.MapKeys() {
+			if err := encodeLegacyParam(values, name+"."+key.String(), v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("parameter %s: can't encode %s", name, v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestEncodeLegacyParams(t *testing.T) {
+	title := "hello"
+
+	values, err := EncodeLegacyParams(map[string]any{
+		"title":        &title,
+		"computer_ids": []int{1, 2, 3},
+		"tags":         map[string]string{"env": "prod", "team": "ops"},
+		"nested":       map[string]any{"list": []any{"a", true}},
+		"force":        true,
+		"limit":        uint8(10),
+		"ratio":        0.5,
+		"code":         []byte("foo"),
+		"unset":        nil,
+	})
+	if err != nil {
+		t.Fatalf("EncodeLegacyParams failed: %v", err)
+	}
+
+	want := "code=Zm9v&computer_ids.1=1&computer_ids.2=2&computer_ids.3=3&force=true&limit=10&nested.list.1=a&nested.list.2=true&ratio=0.5&tags.env=prod&tags.team=ops&title=hello"
+	if got := values.Encode(); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	if _, err := EncodeLegacyParams(map[string]any{"bad": map[int]string{1: "a"}}); err == nil {
+		t.Fatal("expected an error for a map with non-string keys")
+	}
+
+	if _, err := EncodeLegacyParams(map[string]any{"bad": func() {}}); err == nil {
+		t.Fatal("expected an error for a func")
+	}
+}
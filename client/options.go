@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Option configures a client created by NewLandscapeAPIClientWithContext.
+// Options apply to both the client used to log in and the authenticated
+// client that is returned.
+type Option func(*options) error
+
+type options struct {
+	doer          HttpRequestDoer
+	timeout       time.Duration
+	tlsConfig     *tls.Config
+	proxy         *url.URL
+	userAgent     string
+	clientOptions []ClientOption
+	retry         bool
+	retryOptions  []RetryOption
+	tokenCache    TokenCache
+	tokenCacheKey string
+	account       string
+}
+
+func (o *options) tls() *tls.Config {
+	if o.tlsConfig == nil {
+		o.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return o.tlsConfig
+}
+
+// WithHTTPDoer sends requests through doer instead of an http.Client built
+// from the other options. WithTimeout, WithProxy and the TLS options have
+// no effect when it is set.
+func WithHTTPDoer(doer HttpRequestDoer) Option {
+	return func(o *options) error {
+		o.doer = doer
+		return nil
+	}
+}
+
+// WithTimeout limits how long each request, including reading the
+// response body, may take.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		o.timeout = timeout
+		return nil
+	}
+}
+
+// WithTLSConfig replaces the TLS configuration used to connect to Landscape.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) error {
+		o.tlsConfig = cfg.Clone()
+		return nil
+	}
+}
+
+// WithCACertFile trusts the PEM encoded certificates in path, in addition to
+// the system's roots. This is needed for self-hosted Landscape servers using
+// a private CA.
+func WithCACertFile(path string) Option {
+	return func(o *options) error {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+
+		cfg := o.tls()
+		if cfg.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			cfg.RootCAs = pool
+		}
+
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", path)
+		}
+
+		return nil
+	}
+}
+
+// WithClientCertificateFiles authenticates to Landscape with the PEM encoded
+// client certificate and private key in certFile and keyFile.
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	return func(o *options) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		cfg := o.tls()
+		cfg.Certificates = append(cfg.Certificates, cert)
+
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables verification of the server's certificate.
+// It should only be used for testing.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(o *options) error {
+		o.tls().InsecureSkipVerify = skip
+		return nil
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL instead of the one
+// configured in the environment.
+func WithProxy(proxyURL string) Option {
+	return func(o *options) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+
+		o.proxy = u
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithClientOptions passes ClientOptions to both underlying generated
+// clients, for example to add request editors. Use WithHTTPDoer rather
+// than WithHTTPClient to replace the HTTP client.
+func WithClientOptions(opts ...ClientOption) Option {
+	return func(o *options) error {
+		o.clientOptions = append(o.clientOptions, opts...)
+		return nil
+	}
+}
+
+func (o *options) httpDoer() HttpRequestDoer {
+	doer := o.transportDoer()
+	if o.retry {
+		return NewRetryDoer(doer, o.retryOptions...)
+	}
+
+	return doer
+}
+
+func (o *options) transportDoer() HttpRequestDoer {
+	if o.doer != nil {
+		return o.doer
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.tlsConfig != nil {
+		transport.TLSClientConfig = o.tlsConfig
+	}
+	if o.proxy != nil {
+		transport.Proxy = http.ProxyURL(o.proxy)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   o.timeout,
+	}
+}
+
+// baseClientOptions returns the ClientOptions shared by the login and
+// authenticated clients.
+func (o *options) baseClientOptions() []ClientOption {
+	opts := append([]ClientOption{}, o.clientOptions...)
+
+	if o.userAgent != "" {
+		userAgent := o.userAgent
+		opts = append(opts, WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("User-Agent", userAgent)
+			return nil
+		}))
+	}
+
+	return opts
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewLandscapeAPIClientWithContext(t *testing.T) {
+	var userAgents []string
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/login/access-key", func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"token": "test-token"}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/1", func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(V1Script{Id: 1, Title: "diagnostic script"}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	provider := NewAccessKeyProvider("ak", "sk")
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		_, err := NewLandscapeAPIClientWithContext(context.Background(), server.URL, provider)
+		if err == nil {
+			t.Fatal("expected login to fail against an untrusted certificate")
+		}
+	})
+
+	t.Run("custom CA and user agent", func(t *testing.T) {
+		userAgents = nil
+
+		api, err := NewLandscapeAPIClientWithContext(
+			context.Background(),
+			server.URL,
+			provider,
+			WithCACertFile(caFile),
+			WithUserAgent("landscape-test/1.0"),
+		)
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		resp, err := api.GetScriptWithResponse(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected HTTP 200 but received %d", resp.StatusCode())
+		}
+
+		if len(userAgents) != 2 {
+			t.Fatalf("expected 2 requests, got %d", len(userAgents))
+		}
+		for _, ua := range userAgents {
+			if ua != "landscape-test/1.0" {
+				t.Fatalf("expected custom user agent, got %q", ua)
+			}
+		}
+	})
+
+	t.Run("insecure skip verify", func(t *testing.T) {
+		_, err := NewLandscapeAPIClientWithContext(context.Background(), server.URL, provider, WithInsecureSkipVerify(true))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+	})
+
+	t.Run("custom doer", func(t *testing.T) {
+		_, err := NewLandscapeAPIClientWithContext(context.Background(), server.URL, provider, WithHTTPDoer(server.Client()))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+	})
+
+	t.Run("cancelled login", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewLandscapeAPIClientWithContext(ctx, server.URL, provider, WithHTTPDoer(server.Client()))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		api, err := NewLandscapeAPIClientWithContext(
+			context.Background(),
+			server.URL,
+			provider,
+			WithInsecureSkipVerify(true),
+			WithTimeout(50*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/slow", nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+
+		raw := api.ClientInterface.(*Client)
+		if _, err := raw.Client.Do(req); err == nil {
+			t.Fatal("expected request to time out")
+		}
+	})
+
+	t.Run("missing CA file", func(t *testing.T) {
+		_, err := NewLandscapeAPIClientWithContext(context.Background(), server.URL, provider, WithCACertFile(filepath.Join(t.TempDir(), "missing.pem")))
+		if err == nil {
+			t.Fatal("expected error for missing CA file")
+		}
+	})
+}
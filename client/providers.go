@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TokenEnvVar is the environment variable read by EnvTokenProvider.
+const TokenEnvVar = "LANDSCAPE_TOKEN"
+
+// ErrMissingCredentials is returned by a LoginProvider that wasn't given
+// the credentials it needs. ChainProvider skips such providers.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// StaticTokenProvider uses a JWT that was issued beforehand, such as one
+// handed to a CI job, instead of logging in.
+type StaticTokenProvider struct {
+	Token string
+}
+
+func NewStaticTokenProvider(token string) *StaticTokenProvider {
+	return &StaticTokenProvider{Token: token}
+}
+
+// Login implements LoginProvider for StaticTokenProvider.
+func (p *StaticTokenProvider) Login(ctx context.Context, c *ClientWithResponses) (string, error) {
+	if p.Token == "" {
+		return "", fmt.Errorf("%w: no token provided", ErrMissingCredentials)
+	}
+
+	return p.Token, nil
+}
+
+// EnvTokenProvider uses the JWT in an environment variable, which is
+// LANDSCAPE_TOKEN unless Var is set. The variable is read on every login.
+type EnvTokenProvider struct {
+	Var string
+}
+
+func NewEnvTokenProvider() *EnvTokenProvider {
+	return &EnvTokenProvider{Var: TokenEnvVar}
+}
+
+// Login implements LoginProvider for EnvTokenProvider.
+func (p *EnvTokenProvider) Login(ctx context.Context, c *ClientWithResponses) (string, error) {
+	name := p.Var
+	if name == "" {
+		name = TokenEnvVar
+	}
+
+	token := os.Getenv(name)
+	if token == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrMissingCredentials, name)
+	}
+
+	return token, nil
+}
+
+// ChainProvider tries each of its providers in order and uses the first
+// one that logs in. Providers that return ErrMissingCredentials are
+// skipped, but any other error is returned without trying the rest, so
+// that wrong credentials aren't hidden by falling back to other ones.
+type ChainProvider struct {
+	Providers []LoginProvider
+}
+
+func NewChainProvider(providers ...LoginProvider) *ChainProvider {
+	return &ChainProvider{Providers: providers}
+}
+
+// Login implements LoginProvider for ChainProvider.
+func (p *ChainProvider) Login(ctx context.Context, c *ClientWithResponses) (string, error) {
+	res, err := p.LoginWithResponse(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Token, nil
+}
+
+// LoginWithResponse implements LoginResponseProvider for ChainProvider.
+// Providers that only return a token are reported with no accounts.
+func (p *ChainProvider) LoginWithResponse(ctx context.Context, c *ClientWithResponses) (*LoginResponse, error) {
+	for _, provider := range p.Providers {
+		var res *LoginResponse
+		var err error
+
+		if rp, ok := provider.(LoginResponseProvider); ok {
+			res, err = rp.LoginWithResponse(ctx, c)
+		} else {
+			var token string
+			token, err = provider.Login(ctx, c)
+			res = &LoginResponse{Token: token}
+		}
+
+		if err == nil {
+			return res, nil
+		}
+		if !errors.Is(err, ErrMissingCredentials) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no login provider was configured", ErrMissingCredentials)
+}
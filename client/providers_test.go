@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStaticTokenProviders(t *testing.T) {
+	token, err := NewStaticTokenProvider("static-token").Login(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if token != "static-token" {
+		t.Fatalf("expected static-token, got %q", token)
+	}
+
+	if _, err := NewStaticTokenProvider("").Login(context.Background(), nil); !errors.Is(err, ErrMissingCredentials) {
+		t.Fatalf("expected ErrMissingCredentials, got %v", err)
+	}
+
+	t.Setenv(TokenEnvVar, "env-token")
+	token, err = NewEnvTokenProvider().Login(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if token != "env-token" {
+		t.Fatalf("expected env-token, got %q", token)
+	}
+
+	t.Setenv(TokenEnvVar, "")
+	if _, err := NewEnvTokenProvider().Login(context.Background(), nil); !errors.Is(err, ErrMissingCredentials) {
+		t.Fatalf("expected ErrMissingCredentials, got %v", err)
+	}
+}
+
+func TestChainProvider(t *testing.T) {
+	server, logins := newAuthTestServer(t, func(int) time.Time { return time.Now().Add(time.Hour) }, func(string) bool { return false })
+
+	t.Run("skips providers without credentials", func(t *testing.T) {
+		provider := NewChainProvider(
+			NewStaticTokenProvider(""),
+			NewEmailPasswordProvider("", "", nil),
+			NewAccessKeyProvider("ak", "sk"),
+		)
+
+		api, err := NewLandscapeAPIClient(server.URL, provider)
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		resp, err := api.GetScriptWithResponse(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected HTTP 200 but received %d", resp.StatusCode())
+		}
+		if n := logins.Load(); n != 1 {
+			t.Fatalf("expected 1 login, got %d", n)
+		}
+	})
+
+	t.Run("uses the first provider that logs in", func(t *testing.T) {
+		token, err := NewChainProvider(
+			NewStaticTokenProvider("first"),
+			NewStaticTokenProvider("second"),
+		).Login(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if token != "first" {
+			t.Fatalf("expected first, got %q", token)
+		}
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		_, err := NewChainProvider(NewStaticTokenProvider(""), NewAccessKeyProvider("", "")).Login(context.Background(), nil)
+		if !errors.Is(err, ErrMissingCredentials) {
+			t.Fatalf("expected ErrMissingCredentials, got %v", err)
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		var paths []string
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer failing.Close()
+
+		provider := NewChainProvider(
+			NewEmailPasswordProvider("jan@example.com", "wrong", nil),
+			NewAccessKeyProvider("ak", "wrong"),
+		)
+
+		_, err := NewLandscapeAPIClient(failing.URL, provider)
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+		if errors.Is(err, ErrMissingCredentials) {
+			t.Fatalf("expected only login failures, got %v", err)
+		}
+
+		if len(paths) != 1 || paths[0] != "/api/login" {
+			t.Fatalf("expected only the email login to be tried, got %v", paths)
+		}
+	})
+}
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+)
+
+// RetryDoer is an HttpRequestDoer that retries requests that failed with a
+// transient error, waiting with jittered exponential backoff between
+// attempts. Only requests that are safe to send again are retried: GET
+// requests, logins, and legacy actions that were opted in with
+// WithRetryLegacyActions.
+//
+// It can be plugged into a client with WithHTTPClient, or with WithRetry
+// when using NewLandscapeAPIClientWithContext.
+type RetryDoer struct {
+	doer          HttpRequestDoer
+	maxRetries    int
+	baseDelay     time.Duration
+	maxDelay      time.Duration
+	legacyActions map[string]bool
+	sleep         func(ctx context.Context, d time.Duration) error
+}
+
+// RetryOption configures a RetryDoer.
+type RetryOption func(*RetryDoer)
+
+// WithMaxRetries sets how many times a request is retried after the first
+// attempt. The default is 3.
+func WithMaxRetries(n int) RetryOption {
+	return func(d *RetryDoer) {
+		d.maxRetries = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry, which doubles on every
+// later retry up to max. Delays asked for by Retry-After headers are also
+// capped at max. The defaults are 500ms and 30s.
+func WithBackoff(base, max time.Duration) RetryOption {
+	return func(d *RetryDoer) {
+		d.baseDelay = base
+		d.maxDelay = max
+	}
+}
+
+// WithRetryLegacyActions allows retrying the given legacy API actions.
+// Legacy actions are sent as POSTs and may change state, so they are only
+// retried when the caller knows that repeating them is harmless.
+func WithRetryLegacyActions(actions ...string) RetryOption {
+	return func(d *RetryDoer) {
+		for _, action := range actions {
+			d.legacyActions[action] = true
+		}
+	}
+}
+
+// NewRetryDoer returns a RetryDoer that sends requests through doer.
+func NewRetryDoer(doer HttpRequestDoer, opts ...RetryOption) *RetryDoer {
+	d := &RetryDoer{
+		doer:          doer,
+		maxRetries:    defaultMaxRetries,
+		baseDelay:     defaultBaseDelay,
+		maxDelay:      defaultMaxDelay,
+		legacyActions: map[string]bool{},
+		sleep:         sleepContext,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// WithRetry wraps the client's HTTP transport in a RetryDoer configured
+// with opts.
+func WithRetry(opts ...RetryOption) Option {
+	return func(o *options) error {
+		o.retryOptions = append(o.retryOptions, opts...)
+		o.retry = true
+		return nil
+	}
+}
+
+// Do implements HttpRequestDoer for RetryDoer.
+func (d *RetryDoer) Do(req *http.Request) (*http.Response, error) {
+	if !d.retryable(req) {
+		return d.doer.Do(req)
+	}
+
+	ctx := req.Context()
+	attemptReq := req
+
+	for attempt := 0; ; attempt++ {
+		resp, err := d.doer.Do(attemptReq)
+		if attempt >= d.maxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := d.backoff(attempt)
+		if after, ok := retryAfter(resp); ok {
+			delay = min(after, d.maxDelay)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		next, rewindErr := rewindRequest(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := d.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		attemptReq = next
+	}
+}
+
+// retryable reports whether req can be sent more than once.
+func (d *RetryDoer) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		path := strings.TrimSuffix(req.URL.Path, "/")
+		switch {
+		case strings.HasSuffix(path, "/api/login"), strings.HasSuffix(path, "/api/login/access-key"):
+			return true
+		case strings.HasSuffix(path, "/api"):
+			return d.legacyActions[req.URL.Query().Get("action")]
+		}
+	}
+
+	return false
+}
+
+// backoff returns a random delay of up to baseDelay*2^attempt, capped at
+// maxDelay.
+func (d *RetryDoer) backoff(attempt int) time.Duration {
+	ceiling := d.maxDelay
+	if attempt < 32 {
+		if exp := d.baseDelay << attempt; exp > 0 && exp < ceiling {
+			ceiling = exp
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling) + 1
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter returns the delay requested by the response's Retry-After
+// header, which is either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
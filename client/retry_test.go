@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDoer(t *testing.T) {
+	var scriptCalls, legacyCalls, loginCalls atomic.Int32
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/scripts/1", func(w http.ResponseWriter, r *http.Request) {
+		if scriptCalls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(V1Script{Id: 1, Title: "diagnostic script"}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/2", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	handler.HandleFunc("/api/login/access-key", func(w http.ResponseWriter, r *http.Request) {
+		var body AccessKeyLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.AccessKey != "ak" {
+			t.Errorf("login body not replayed: %+v (%v)", body, err)
+		}
+
+		if loginCalls.Add(1) < 2 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"token": "test-token"}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		legacyCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	newDoer := func(delays *[]time.Duration, opts ...RetryOption) *RetryDoer {
+		d := NewRetryDoer(server.Client(), opts...)
+		d.sleep = func(ctx context.Context, delay time.Duration) error {
+			*delays = append(*delays, delay)
+			return nil
+		}
+		return d
+	}
+
+	t.Run("retries GET until success", func(t *testing.T) {
+		var delays []time.Duration
+		api, err := NewClientWithResponses(server.URL, WithHTTPClient(newDoer(&delays, WithBackoff(time.Second, 4*time.Second))))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		resp, err := api.GetScriptWithResponse(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected HTTP 200 but received %d", resp.StatusCode())
+		}
+
+		if len(delays) != 2 {
+			t.Fatalf("expected 2 retries, got %d", len(delays))
+		}
+		for i, delay := range delays {
+			if ceiling := time.Second << i; delay <= 0 || delay > ceiling {
+				t.Fatalf("retry %d waited %v, expected (0, %v]", i, delay, ceiling)
+			}
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		var delays []time.Duration
+		api, err := NewClientWithResponses(server.URL, WithHTTPClient(newDoer(&delays, WithMaxRetries(2))))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		resp, err := api.GetScriptWithResponse(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusTooManyRequests {
+			t.Fatalf("expected HTTP 429 but received %d", resp.StatusCode())
+		}
+		if len(delays) != 2 || delays[0] != defaultMaxDelay {
+			t.Fatalf("expected 2 retries honouring Retry-After up to the max delay, got %v", delays)
+		}
+	})
+
+	t.Run("respects context deadline", func(t *testing.T) {
+		var delays []time.Duration
+		api, err := NewClientWithResponses(server.URL, WithHTTPClient(newDoer(&delays)))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		resp, err := api.GetScriptWithResponse(ctx, 2)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusTooManyRequests {
+			t.Fatalf("expected HTTP 429 but received %d", resp.StatusCode())
+		}
+		if len(delays) != 0 {
+			t.Fatalf("expected no retries past the deadline, got %v", delays)
+		}
+	})
+
+	t.Run("retries login with body", func(t *testing.T) {
+		var delays []time.Duration
+		api, err := NewClientWithResponses(server.URL, WithHTTPClient(newDoer(&delays)))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		token, err := NewAccessKeyProvider("ak", "sk").Login(context.Background(), api)
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if token != "test-token" {
+			t.Fatalf("unexpected token %q", token)
+		}
+		if len(delays) != 1 || delays[0] != 7*time.Second {
+			t.Fatalf("expected 1 retry honouring Retry-After, got %v", delays)
+		}
+	})
+
+	t.Run("legacy actions only retried when opted in", func(t *testing.T) {
+		var delays []time.Duration
+		api, err := NewClientWithResponses(server.URL, WithHTTPClient(newDoer(&delays, WithRetryLegacyActions("GetScripts"))))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+
+		legacyCalls.Store(0)
+		resp, err := api.InvokeLegacyAction(context.Background(), LegacyActionParams("CreateScript"))
+		if err != nil {
+			t.Fatalf("InvokeLegacyAction failed: %v", err)
+		}
+		resp.Body.Close()
+		if n := legacyCalls.Load(); n != 1 {
+			t.Fatalf("expected CreateScript to be sent once, got %d", n)
+		}
+
+		legacyCalls.Store(0)
+		resp, err = api.InvokeLegacyAction(context.Background(), LegacyActionParams("GetScripts"))
+		if err != nil {
+			t.Fatalf("InvokeLegacyAction failed: %v", err)
+		}
+		resp.Body.Close()
+		if n := legacyCalls.Load(); n != defaultMaxRetries+1 {
+			t.Fatalf("expected GetScripts to be sent %d times, got %d", defaultMaxRetries+1, n)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Script is implemented by *V1Script and *V2Script, giving access to the
+// fields they have in common without knowing which kind of script it is.
+// Use AsScript to get one from a response.
+type Script interface {
+	GetID() int
+	GetTitle() string
+	// GetAttachments returns the script's attachments. V1 scripts only
+	// report attachment filenames, so their attachments have no ID.
+	GetAttachments() []ScriptAttachment
+	GetTimeLimit() int
+	GetUsername() string
+	GetAccessGroup() string
+	// GetStatus returns V1 for legacy scripts, or the V2 script's status:
+	// ACTIVE, ARCHIVED or REDACTED.
+	GetStatus() string
+	// GetVersion returns the V2 script's version number, or 0 for V1
+	// scripts, which aren't versioned.
+	GetVersion() int
+}
+
+var (
+	_ Script = (*V1Script)(nil)
+	_ Script = (*V2Script)(nil)
+)
+
+// Discriminator returns the script's status: V1 for V1 scripts, and
+// ACTIVE, ARCHIVED or REDACTED for V2 scripts. Scripts that don't report a
+// status get one based on the fields they have.
+func (t ScriptResult) Discriminator() (string, error) {
+	var discriminator struct {
+		Discriminator string `json:"status"`
+	}
+	if err := json.Unmarshal(t.union, &discriminator); err != nil {
+		return "", err
+	}
+
+	if discriminator.Discriminator == "" {
+		return inferScriptStatus(t.union)
+	}
+
+	return discriminator.Discriminator, nil
+}
+
+// ValueByDiscriminator returns the union data inside the ScriptResult as a
+// V1Script or V2Script, chosen by the script's status.
+func (t ScriptResult) ValueByDiscriminator() (interface{}, error) {
+	discriminator, err := t.Discriminator()
+	if err != nil {
+		return nil, err
+	}
+
+	switch discriminator {
+	case string(V1):
+		return t.AsV1Script()
+	case string(ACTIVE), string(ARCHIVED), string(REDACTED):
+		return t.AsV2Script()
+	default:
+		return nil, fmt.Errorf("unknown script status: %q", discriminator)
+	}
+}
+
+// AsScript returns the union data inside the ScriptResult as a *V1Script
+// or *V2Script, chosen by the script's status.
+func (t ScriptResult) AsScript() (Script, error) {
+	value, err := t.ValueByDiscriminator()
+	if err != nil {
+		return nil, err
+	}
+
+	switch script := value.(type) {
+	case V1Script:
+		return &script, nil
+	case V2Script:
+		return &script, nil
+	default:
+		return nil, fmt.Errorf("unexpected script type %T", value)
+	}
+}
+
+// AsScript returns the union data inside the LegacyActionResponse as a
+// *V1Script or *V2Script, chosen by the script's status.
+func (t LegacyActionResponse) AsScript() (Script, error) {
+	result, err := t.AsScriptResult()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.AsScript()
+}
+
+// inferScriptStatus guesses the status of a script that doesn't report
+// one, based on fields that only V2 scripts have.
+func inferScriptStatus(raw json.RawMessage) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", err
+	}
+
+	for _, key := range []string{"version_number", "created_by", "last_edited_at"} {
+		if _, ok := fields[key]; ok {
+			return string(ACTIVE), nil
+		}
+	}
+
+	return string(V1), nil
+}
+
+// GetID implements Script for V1Script.
+func (s *V1Script) GetID() int { return s.Id }
+
+// GetTitle implements Script for V1Script.
+func (s *V1Script) GetTitle() string { return s.Title }
+
+// GetAttachments implements Script for V1Script.
+func (s *V1Script) GetAttachments() []ScriptAttachment {
+	if s.Attachments == nil {
+		return nil
+	}
+
+	attachments := make([]ScriptAttachment, 0, len(*s.Attachments))
+	for _, filename := range *s.Attachments {
+		attachments = append(attachments, ScriptAttachment{Filename: filename})
+	}
+	return attachments
+}
+
+// GetTimeLimit implements Script for V1Script.
+func (s *V1Script) GetTimeLimit() int { return deref(s.TimeLimit) }
+
+// GetUsername implements Script for V1Script.
+func (s *V1Script) GetUsername() string { return deref(s.Username) }
+
+// GetAccessGroup implements Script for V1Script.
+func (s *V1Script) GetAccessGroup() string { return deref(s.AccessGroup) }
+
+// GetStatus implements Script for V1Script.
+func (s *V1Script) GetStatus() string { return string(V1) }
+
+// GetVersion implements Script for V1Script.
+func (s *V1Script) GetVersion() int { return 0 }
+
+// GetID implements Script for V2Script.
+func (s *V2Script) GetID() int { return s.Id }
+
+// GetTitle implements Script for V2Script.
+func (s *V2Script) GetTitle() string { return s.Title }
+
+// GetAttachments implements Script for V2Script.
+func (s *V2Script) GetAttachments() []ScriptAttachment {
+	if s.Attachments == nil {
+		return nil
+	}
+	return append([]ScriptAttachment{}, *s.Attachments...)
+}
+
+// GetTimeLimit implements Script for V2Script.
+func (s *V2Script) GetTimeLimit() int { return deref(s.TimeLimit) }
+
+// GetUsername implements Script for V2Script.
+func (s *V2Script) GetUsername() string { return deref(s.Username) }
+
+// GetAccessGroup implements Script for V2Script.
+func (s *V2Script) GetAccessGroup() string { return deref(s.AccessGroup) }
+
+// GetStatus implements Script for V2Script.
+func (s *V2Script) GetStatus() string { return string(s.Status) }
+
+// GetVersion implements Script for V2Script.
+func (s *V2Script) GetVersion() int { return deref(s.VersionNumber) }
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
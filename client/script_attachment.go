@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ListScriptAttachmentsResponse is the response to a script attachment
+// listing request.
+type ListScriptAttachmentsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]ScriptAttachment
+}
+
+// Status returns HTTPResponse.Status
+func (r ListScriptAttachmentsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListScriptAttachmentsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// NewListScriptAttachmentsRequest generates requests for listing the
+// attachments of a script with their IDs.
+func NewListScriptAttachmentsRequest(server string, scriptID int) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse(fmt.Sprintf("./api/scripts/%d/attachments", scriptID))
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest("GET", queryURL.String(), nil)
+}
+
+// ListScriptAttachmentsWithResponse lists the attachments of a script with
+// their IDs, which V1 scripts don't include.
+func (c *ClientWithResponses) ListScriptAttachmentsWithResponse(ctx context.Context, scriptID int, reqEditors ...RequestEditorFn) (*ListScriptAttachmentsResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support listing script attachments")
+	}
+
+	req, err := NewListScriptAttachmentsRequest(raw.Server, scriptID)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, body, err := raw.doJSON(ctx, req, reqEditors)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListScriptAttachmentsResponse{
+		Body:         body,
+		HTTPResponse: rsp,
+	}
+
+	if strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == http.StatusOK {
+		var dest []ScriptAttachment
+		if err := json.Unmarshal(body, &dest); err != nil {
+			// With paging, the attachments are sent as the page's results.
+			var page struct {
+				Results []ScriptAttachment `json:"results"`
+			}
+			if err := json.Unmarshal(body, &page); err != nil {
+				return nil, err
+			}
+			dest = page.Results
+		}
+		response.JSON200 = &dest
+	}
+
+	return response, nil
+}
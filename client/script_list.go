@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListScriptsParams filter and page the scripts returned by ListScripts.
+// Empty fields don't filter.
+type ListScriptsParams struct {
+	// Search matches scripts whose title contains it.
+	Search string
+	// ScriptType only returns V1 or V2 scripts.
+	ScriptType ScriptType
+	// Status only returns V2 scripts with this status: ACTIVE, ARCHIVED or
+	// REDACTED.
+	Status V2ScriptStatus
+	// CreatedBy only returns scripts created by the user with this name
+	// or email.
+	CreatedBy string
+	// AccessGroup only returns scripts in this access group.
+	AccessGroup string
+	// Limit is the largest number of scripts to return. Zero leaves the
+	// server's default.
+	Limit int
+	// Offset is the number of matching scripts to skip.
+	Offset int
+}
+
+// ScriptList is a page of scripts returned by ListScripts.
+type ScriptList struct {
+	// Count is the number of scripts matching the filters, across all
+	// pages.
+	Count int `json:"count"`
+	// Next is the URL of the next page, if there is one.
+	Next    *string        `json:"next,omitempty"`
+	Results []ScriptResult `json:"results"`
+}
+
+// ListScriptsResponse is the response to a script listing request.
+type ListScriptsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ScriptList
+}
+
+// Status returns HTTPResponse.Status
+func (r ListScriptsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListScriptsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// NewListScriptsRequest generates requests for listing scripts.
+func NewListScriptsRequest(server string, params ListScriptsParams) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse("./api/scripts")
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if params.Search != "" {
+		query.Set("search", params.Search)
+	}
+	if params.ScriptType != "" {
+		query.Set("script_type", strings.ToLower(string(params.ScriptType)))
+	}
+	if params.Status != "" {
+		query.Set("status", string(params.Status))
+	}
+	if params.CreatedBy != "" {
+		query.Set("created_by", params.CreatedBy)
+	}
+	if params.AccessGroup != "" {
+		query.Set("access_group", params.AccessGroup)
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Offset > 0 {
+		query.Set("offset", strconv.Itoa(params.Offset))
+	}
+	queryURL.RawQuery = query.Encode()
+
+	return http.NewRequest("GET", queryURL.String(), nil)
+}
+
+// ListScriptsWithResponse lists the scripts matching params.
+func (c *ClientWithResponses) ListScriptsWithResponse(ctx context.Context, params ListScriptsParams, reqEditors ...RequestEditorFn) (*ListScriptsResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support listing scripts")
+	}
+
+	req, err := NewListScriptsRequest(raw.Server, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, body, err := raw.doJSON(ctx, req, reqEditors)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListScriptsResponse{
+		Body:         body,
+		HTTPResponse: rsp,
+	}
+
+	if strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == http.StatusOK {
+		var dest ScriptList
+		if err := json.Unmarshal(body, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+	}
+
+	return response, nil
+}
+
+// doJSON sends req with the client's request editors and reads the whole
+// response body.
+func (c *Client) doJSON(ctx context.Context, req *http.Request, reqEditors []RequestEditorFn) (*http.Response, []byte, error) {
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, nil, err
+	}
+
+	rsp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rsp.Body.Close()
+
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rsp, body, nil
+}
+
+// ScriptPage is a page of scripts returned by ScriptService.List.
+type ScriptPage struct {
+	Scripts []Script
+	// Count is the number of scripts matching the filters, across all
+	// pages.
+	Count int
+	// More reports whether there are scripts after this page.
+	More bool
+}
+
+// List returns the page of scripts matching params.
+func (s *ScriptService) List(ctx context.Context, params ListScriptsParams) (*ScriptPage, error) {
+	list, err := ResponseValue[ScriptList](s.client.ListScriptsWithResponse(ctx, params))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ScriptPage{
+		Scripts: make([]Script, 0, len(list.Results)),
+		Count:   list.Count,
+	}
+	for _, result := range list.Results {
+		script, err := result.AsScript()
+		if err != nil {
+			return nil, err
+		}
+		page.Scripts = append(page.Scripts, script)
+	}
+
+	page.More = morePages(len(page.Scripts), params.Offset+len(page.Scripts), list.Count, list.Next)
+
+	return page, nil
+}
+
+// morePages reports whether a listing has pages after one with n results
+// that ended at offset end, out of count results across all pages. The
+// count decides it when the server reports one, so a server that keeps
+// linking to a next page can't make the caller fetch pages forever, and an
+// empty page always ends the listing.
+func morePages(n, end, count int, next *string) bool {
+	if n == 0 {
+		return false
+	}
+	if count > 0 {
+		return end < count
+	}
+	return next != nil
+}
+
+// All returns an iterator over every script matching params, fetching
+// pages of params.Limit scripts as it goes, starting at params.Offset.
+// Iteration stops after the first error.
+func (s *ScriptService) All(ctx context.Context, params ListScriptsParams) iter.Seq2[Script, error] {
+	return func(yield func(Script, error) bool) {
+		for {
+			page, err := s.List(ctx, params)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, script := range page.Scripts {
+				if !yield(script, nil) {
+					return
+				}
+			}
+
+			if !page.More {
+				return
+			}
+			params.Offset += len(page.Scripts)
+		}
+	}
+}
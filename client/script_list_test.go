@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestListScripts(t *testing.T) {
+	scripts := []map[string]any{
+		{"id": 1, "title": "backup", "status": "V1"},
+		{"id": 2, "title": "backup v2", "status": "ACTIVE", "version_number": 1},
+		{"id": 3, "title": "cleanup", "status": "ARCHIVED", "version_number": 4},
+		{"id": 4, "title": "restore", "status": "ACTIVE", "version_number": 2},
+		{"id": 5, "title": "upgrade", "status": "REDACTED", "version_number": 1},
+	}
+
+	var queries []url.Values
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queries = append(queries, query)
+
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			limit = len(scripts)
+		}
+		end := min(offset+limit, len(scripts))
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{
+			"count":   len(scripts),
+			"results": scripts[offset:end],
+		}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	t.Run("sends filters", func(t *testing.T) {
+		queries = nil
+
+		page, err := api.Scripts().List(context.Background(), ListScriptsParams{
+			Search:      "backup",
+			ScriptType:  ScriptTypeV2,
+			Status:      ACTIVE,
+			CreatedBy:   "jan@example.com",
+			AccessGroup: "global",
+			Limit:       2,
+			Offset:      1,
+		})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+
+		want := url.Values{
+			"search":       {"backup"},
+			"script_type":  {"v2"},
+			"status":       {"ACTIVE"},
+			"created_by":   {"jan@example.com"},
+			"access_group": {"global"},
+			"limit":        {"2"},
+			"offset":       {"1"},
+		}
+		if got := queries[0]; got.Encode() != want.Encode() {
+			t.Fatalf("expected query %v, got %v", want, got)
+		}
+
+		if page.Count != 5 || !page.More || len(page.Scripts) != 2 {
+			t.Fatalf("unexpected page %+v", page)
+		}
+		if _, ok := page.Scripts[0].(*V2Script); !ok || page.Scripts[0].GetID() != 2 {
+			t.Fatalf("expected V2 script 2, got %+v", page.Scripts[0])
+		}
+	})
+
+	t.Run("iterates over all pages", func(t *testing.T) {
+		queries = nil
+
+		var ids []int
+		for script, err := range api.Scripts().All(context.Background(), ListScriptsParams{Limit: 2}) {
+			if err != nil {
+				t.Fatalf("All failed: %v", err)
+			}
+			ids = append(ids, script.GetID())
+		}
+
+		if len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+			t.Fatalf("expected scripts 1 to 5, got %v", ids)
+		}
+		if len(queries) != 3 {
+			t.Fatalf("expected 3 pages, got %d", len(queries))
+		}
+	})
+
+	t.Run("stops early", func(t *testing.T) {
+		queries = nil
+
+		for range api.Scripts().All(context.Background(), ListScriptsParams{Limit: 2}) {
+			break
+		}
+
+		if len(queries) != 1 {
+			t.Fatalf("expected 1 page, got %d", len(queries))
+		}
+	})
+}
+
+func TestListScriptsStopsPaging(t *testing.T) {
+	next := "http://example.com/api/scripts?offset=2"
+
+	tests := []struct {
+		name      string
+		page      map[string]any
+		wantIDs   int
+		wantPages int
+	}{
+		{
+			name:      "offset reaches the count",
+			page:      map[string]any{"count": 4, "next": next, "results": []map[string]any{{"id": 1, "status": "V1"}, {"id": 2, "status": "V1"}}},
+			wantIDs:   4,
+			wantPages: 2,
+		},
+		{
+			name:      "empty page",
+			page:      map[string]any{"count": 10, "next": next, "results": []map[string]any{}},
+			wantPages: 1,
+		},
+		{
+			name:      "empty page without a count",
+			page:      map[string]any{"next": next, "results": []map[string]any{}},
+			wantPages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := 0
+
+			// The server ignores the offset, so it returns the same page
+			// however far the client has got.
+			handler := http.NewServeMux()
+			handler.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+				pages++
+				if pages > 10 {
+					http.Error(w, "kept fetching pages", http.StatusBadRequest)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(tt.page); err != nil {
+					t.Fatalf("failed to write response: %v", err)
+				}
+			})
+
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			api, err := NewClientWithResponses(server.URL)
+			if err != nil {
+				t.Fatalf("failed to init client: %v", err)
+			}
+
+			n := 0
+			for _, err := range api.Scripts().All(context.Background(), ListScriptsParams{Limit: 2}) {
+				if err != nil {
+					t.Fatalf("All failed: %v", err)
+				}
+				n++
+			}
+
+			if n != tt.wantIDs || pages != tt.wantPages {
+				t.Fatalf("expected %d scripts in %d pages, got %d in %d", tt.wantIDs, tt.wantPages, n, pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// ScriptMigration is the result of migrating a V1 script to a V2 script.
+type ScriptMigration struct {
+	// V1ID is the ID of the original V1 script.
+	V1ID int `json:"v1_id"`
+	// V2ID is the ID of the V2 script created from it, or zero if it
+	// wasn't created.
+	V2ID  int    `json:"v2_id,omitempty"`
+	Title string `json:"title"`
+	// Attachments are the filenames of the attachments copied to the V2
+	// script.
+	Attachments []string `json:"attachments"`
+	// Archived is whether the V1 script was archived after migrating it.
+	Archived bool `json:"archived"`
+}
+
+// MigrateScriptParams are the parameters for migrating a V1 script to a V2
+// script.
+type MigrateScriptParams struct {
+	// Archive archives the V1 script once the V2 script has been created
+	// with all of its attachments.
+	Archive bool
+}
+
+// Migrate creates a V2 script with the code, attachments, title, time
+// limit, username and access group of the V1 script with the given ID. The
+// V1 script's code and attachments are read before anything is created, so
+// a script that can't be read is left as it is.
+//
+// If creating the V2 script succeeds but a later step fails, the error is
+// returned with the migration so far, so that the new script isn't lost.
+func (s *ScriptService) Migrate(ctx context.Context, id int, params MigrateScriptParams) (*ScriptMigration, error) {
+	script, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := script.(*V1Script); !ok {
+		return nil, fmt.Errorf("script %d is already a V2 script", id)
+	}
+
+	migration := &ScriptMigration{V1ID: id, Title: script.GetTitle(), Attachments: []string{}}
+
+	code, err := s.GetCode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the code of script %d: %w", id, err)
+	}
+	if ShebangInterpreter(code) == "" {
+		return nil, fmt.Errorf("script %d has no shebang line naming its interpreter", id)
+	}
+
+	type attachment struct {
+		filename string
+		contents []byte
+	}
+	var attachments []attachment
+	sources, err := s.ListAttachments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range sources {
+		var contents bytes.Buffer
+		if _, err := s.DownloadAttachment(ctx, id, a.Id, &contents); err != nil {
+			return nil, fmt.Errorf("failed to download attachment %s of script %d: %w", a.Filename, id, err)
+		}
+		attachments = append(attachments, attachment{filename: a.Filename, contents: contents.Bytes()})
+	}
+
+	created, err := s.Create(ctx, CreateScriptParams{
+		Title:       script.GetTitle(),
+		Code:        code,
+		TimeLimit:   script.GetTimeLimit(),
+		Username:    script.GetUsername(),
+		AccessGroup: script.GetAccessGroup(),
+		ScriptType:  ScriptTypeV2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a V2 script from script %d: %w", id, err)
+	}
+	migration.V2ID = created.GetID()
+
+	for _, a := range attachments {
+		if _, err := s.AddAttachment(ctx, migration.V2ID, a.filename, a.contents); err != nil {
+			return migration, fmt.Errorf("failed to attach %s to script %d: %w", a.filename, migration.V2ID, err)
+		}
+		migration.Attachments = append(migration.Attachments, a.filename)
+	}
+
+	if params.Archive {
+		if err := s.Archive(ctx, id); err != nil {
+			return migration, fmt.Errorf("migrated to script %d but failed to archive script %d: %w", migration.V2ID, id, err)
+		}
+		migration.Archived = true
+	}
+
+	return migration, nil
+}
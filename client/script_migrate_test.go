@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScriptMigrate(t *testing.T) {
+	var (
+		created     url.Values
+		attached    []string
+		archived    []string
+		failAttach  bool
+		failArchive bool
+	)
+
+	writeJSON := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		switch query.Get("action") {
+		case "GetScriptCode":
+			writeJSON(w, http.StatusOK, "#!/bin/bash\ntar c /srv")
+		case "CreateScript":
+			created = query
+			writeJSON(w, http.StatusOK, map[string]any{"id": 50, "title": query.Get("title"), "status": "ACTIVE", "version_number": 1})
+		case "CreateScriptAttachment":
+			if failAttach {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"message": "try again"})
+				return
+			}
+			filename, contents, _ := strings.Cut(query.Get("file"), "$$")
+			decoded, _ := base64.StdEncoding.DecodeString(contents)
+			attached = append(attached, query.Get("script_id")+" "+filename+" "+string(decoded))
+			writeJSON(w, http.StatusOK, filename)
+		default:
+			writeJSON(w, http.StatusBadRequest, map[string]any{"message": "unknown action"})
+		}
+	})
+	handler.HandleFunc("/api/scripts/10", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 10, "title": "backup", "status": "V1", "time_limit": 600, "username": "root", "access_group": "servers",
+			"attachments": []string{"backup.conf", "exclude.txt"},
+		})
+	})
+	handler.HandleFunc("/api/scripts/10/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]any{{"id": 4, "filename": "exclude.txt"}, {"id": 3, "filename": "backup.conf"}})
+	})
+	handler.HandleFunc("/api/scripts/10/attachments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, "contents of "+r.PathValue("id"))
+	})
+	handler.HandleFunc("/api/scripts/10:archive", func(w http.ResponseWriter, r *http.Request) {
+		if failArchive {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"message": "script can't be archived"})
+			return
+		}
+		archived = append(archived, "10")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler.HandleFunc("/api/scripts/11", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 11, "title": "restore", "status": "V1", "attachments": []string{"restore.conf"}})
+	})
+	handler.HandleFunc("/api/scripts/11/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"count": 1, "results": []map[string]any{{"id": 5, "filename": "other.conf"}}})
+	})
+	handler.HandleFunc("/api/scripts/12", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 12, "title": "new", "status": "ACTIVE", "version_number": 2})
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	t.Run("migrate and archive", func(t *testing.T) {
+		migration, err := api.Scripts().Migrate(context.Background(), 10, MigrateScriptParams{Archive: true})
+		if err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		if migration.V1ID != 10 || migration.V2ID != 50 || !migration.Archived || !slices.Equal(migration.Attachments, []string{"backup.conf", "exclude.txt"}) {
+			t.Fatalf("unexpected migration %+v", migration)
+		}
+
+		code, _ := base64.StdEncoding.DecodeString(created.Get("code"))
+		if created.Get("script_type") != "V2" || created.Get("title") != "backup" || string(code) != "#!/bin/bash\ntar c /srv" ||
+			created.Get("time_limit") != "600" || created.Get("username") != "root" || created.Get("access_group") != "servers" {
+			t.Fatalf("unexpected create %v (code %q)", created, code)
+		}
+		if !slices.Equal(attached, []string{"50 backup.conf contents of 3", "50 exclude.txt contents of 4"}) {
+			t.Fatalf("unexpected attachments %q", attached)
+		}
+		if !slices.Equal(archived, []string{"10"}) {
+			t.Fatalf("unexpected archives %q", archived)
+		}
+	})
+
+	t.Run("V1 script is kept when an attachment fails", func(t *testing.T) {
+		attached, archived, failAttach = nil, nil, true
+		defer func() { failAttach = false }()
+
+		migration, err := api.Scripts().Migrate(context.Background(), 10, MigrateScriptParams{Archive: true})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if migration == nil || migration.V2ID != 50 || migration.Archived || archived != nil {
+			t.Fatalf("unexpected migration %+v (archives %q)", migration, archived)
+		}
+	})
+
+	t.Run("archive fails", func(t *testing.T) {
+		attached, archived, failArchive = nil, nil, true
+		defer func() { failArchive = false }()
+
+		migration, err := api.Scripts().Migrate(context.Background(), 10, MigrateScriptParams{Archive: true})
+		if err == nil || !strings.Contains(err.Error(), "failed to archive script 10") || !strings.Contains(err.Error(), "script can't be archived") {
+			t.Fatalf("expected an error about archiving script 10, got %v", err)
+		}
+		if migration == nil || migration.V2ID != 50 || migration.Archived || len(migration.Attachments) != 2 {
+			t.Fatalf("unexpected migration %+v", migration)
+		}
+	})
+
+	t.Run("attachment without an ID", func(t *testing.T) {
+		created = nil
+		if _, err := api.Scripts().Migrate(context.Background(), 11, MigrateScriptParams{}); err == nil || !strings.Contains(err.Error(), "restore.conf") {
+			t.Fatalf("expected an error about restore.conf, got %v", err)
+		}
+		if created != nil {
+			t.Fatalf("expected nothing to be created, got %v", created)
+		}
+	})
+
+	t.Run("V2 script", func(t *testing.T) {
+		if _, err := api.Scripts().Migrate(context.Background(), 12, MigrateScriptParams{}); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
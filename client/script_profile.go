@@ -0,0 +1,625 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ScriptProfileTriggerType is what makes a script profile run its script.
+type ScriptProfileTriggerType string
+
+const (
+	// TriggerEvent runs the script when an event happens on a computer,
+	// such as it being enrolled.
+	TriggerEvent ScriptProfileTriggerType = "event"
+	// TriggerRecurring runs the script on a cron-style schedule.
+	TriggerRecurring ScriptProfileTriggerType = "recurring"
+	// TriggerOneTime runs the script once, at a given time.
+	TriggerOneTime ScriptProfileTriggerType = "one_time"
+)
+
+// EventPostEnrollment is the event of a computer being enrolled.
+const EventPostEnrollment = "post_enrollment"
+
+// ScriptProfileTrigger is when a script profile runs its script. Which
+// fields are used depends on TriggerType.
+type ScriptProfileTrigger struct {
+	TriggerType ScriptProfileTriggerType `json:"trigger_type"`
+	// EventType is the event that runs the script, for event triggers.
+	EventType string `json:"event_type,omitempty"`
+	// Interval is the cron expression the script runs on, such as
+	// "0 3 * * 1", for recurring triggers.
+	Interval string `json:"interval,omitempty"`
+	// StartAfter is when a recurring trigger starts, in RFC 3339 format.
+	StartAfter string `json:"start_after,omitempty"`
+	// Timestamp is when a one-time trigger runs, in RFC 3339 format.
+	Timestamp string `json:"timestamp,omitempty"`
+	// NextRun is when the script runs next, as reported by the server.
+	NextRun *string `json:"next_run,omitempty"`
+}
+
+// Validate checks that the trigger has the fields its type needs.
+func (t ScriptProfileTrigger) Validate() error {
+	switch t.TriggerType {
+	case TriggerEvent:
+		if t.EventType == "" {
+			return fmt.Errorf("event triggers need an event type")
+		}
+	case TriggerRecurring:
+		// The server checks the cron expression itself.
+		if strings.TrimSpace(t.Interval) == "" {
+			return fmt.Errorf("recurring triggers need an interval")
+		}
+	case TriggerOneTime:
+		if t.Timestamp == "" {
+			return fmt.Errorf("one-time triggers need a timestamp")
+		}
+	default:
+		return fmt.Errorf("trigger type must be %s, %s or %s", TriggerEvent, TriggerRecurring, TriggerOneTime)
+	}
+	return nil
+}
+
+// ScriptProfileDetails is a script profile, which runs a script on the
+// computers it targets when its trigger fires. ScriptProfile, which V2
+// scripts list their profiles with, only has the ID and title.
+type ScriptProfileDetails struct {
+	Id       int                  `json:"id"`
+	Title    string               `json:"title"`
+	ScriptId int                  `json:"script_id"`
+	Trigger  ScriptProfileTrigger `json:"trigger"`
+	// Tags are the tags of the computers the script runs on, unless
+	// AllComputers is set.
+	Tags         []string `json:"tags"`
+	AllComputers bool     `json:"all_computers"`
+	Username     string   `json:"username"`
+	TimeLimit    int      `json:"time_limit"`
+	Archived     bool     `json:"archived,omitempty"`
+	CreatedAt    *string  `json:"created_at,omitempty"`
+	LastEditedAt *string  `json:"last_edited_at,omitempty"`
+}
+
+// ScriptProfileList is a page of script profiles.
+type ScriptProfileList struct {
+	Count   int                    `json:"count"`
+	Next    *string                `json:"next,omitempty"`
+	Results []ScriptProfileDetails `json:"results"`
+}
+
+// ScriptProfileResponse is the response to a request for, or changing, a
+// script profile.
+type ScriptProfileResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ScriptProfileDetails
+}
+
+// Status returns HTTPResponse.Status
+func (r ScriptProfileResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ScriptProfileResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// ListScriptProfilesResponse is the response to a script profile listing
+// request.
+type ListScriptProfilesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ScriptProfileList
+}
+
+// Status returns HTTPResponse.Status
+func (r ListScriptProfilesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListScriptProfilesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// ListScriptProfileActivitiesResponse is the response to a request for the
+// activities of a script profile.
+type ListScriptProfileActivitiesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ActivityList
+}
+
+// Status returns HTTPResponse.Status
+func (r ListScriptProfileActivitiesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListScriptProfileActivitiesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// NewListScriptProfilesRequest generates requests for a page of script
+// profiles, starting at offset.
+func NewListScriptProfilesRequest(server string, offset int) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse("./api/script-profiles")
+	if err != nil {
+		return nil, err
+	}
+	queryURL.RawQuery = offsetQuery(offset).Encode()
+
+	return http.NewRequest("GET", queryURL.String(), nil)
+}
+
+// NewGetScriptProfileRequest generates requests for a script profile.
+func NewGetScriptProfileRequest(server string, id int) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse(fmt.Sprintf("./api/script-profiles/%d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest("GET", queryURL.String(), nil)
+}
+
+// NewCreateScriptProfileRequest generates requests for creating a script
+// profile from body, which is encoded as JSON.
+func NewCreateScriptProfileRequest(server string, body any) (*http.Request, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse("./api/script-profiles")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// NewEditScriptProfileRequest generates requests for changing the fields of
+// a script profile that are in body, which is encoded as JSON.
+func NewEditScriptProfileRequest(server string, id int, body any) (*http.Request, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse(fmt.Sprintf("./api/script-profiles/%d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", queryURL.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// NewArchiveScriptProfileRequest generates requests for archiving a script
+// profile.
+func NewArchiveScriptProfileRequest(server string, id int) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse(fmt.Sprintf("./api/script-profiles/%d:archive", id))
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest("POST", queryURL.String(), nil)
+}
+
+// NewListScriptProfileActivitiesRequest generates requests for a page of the
+// activities of a script profile, starting at offset.
+func NewListScriptProfileActivitiesRequest(server string, id, offset int) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	queryURL, err := serverURL.Parse(fmt.Sprintf("./api/script-profiles/%d/activities", id))
+	if err != nil {
+		return nil, err
+	}
+	queryURL.RawQuery = offsetQuery(offset).Encode()
+
+	return http.NewRequest("GET", queryURL.String(), nil)
+}
+
+// ListScriptProfilesWithResponse lists script profiles, starting at offset.
+func (c *ClientWithResponses) ListScriptProfilesWithResponse(ctx context.Context, offset int, reqEditors ...RequestEditorFn) (*ListScriptProfilesResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support listing script profiles")
+	}
+
+	req, err := NewListScriptProfilesRequest(raw.Server, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, body, err := raw.doJSON(ctx, req, reqEditors)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListScriptProfilesResponse{Body: body, HTTPResponse: rsp}
+	if isJSON200(rsp) {
+		var dest ScriptProfileList
+		if err := decodeList(body, &dest, &dest.Results); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+	}
+
+	return response, nil
+}
+
+// GetScriptProfileWithResponse gets a script profile.
+func (c *ClientWithResponses) GetScriptProfileWithResponse(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*ScriptProfileResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support getting script profiles")
+	}
+
+	req, err := NewGetScriptProfileRequest(raw.Server, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw.doScriptProfile(ctx, req, reqEditors)
+}
+
+// CreateScriptProfileWithResponse creates a script profile from a JSON
+// body.
+func (c *ClientWithResponses) CreateScriptProfileWithResponse(ctx context.Context, body any, reqEditors ...RequestEditorFn) (*ScriptProfileResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support creating script profiles")
+	}
+
+	req, err := NewCreateScriptProfileRequest(raw.Server, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw.doScriptProfile(ctx, req, reqEditors)
+}
+
+// EditScriptProfileWithResponse changes the fields of a script profile that
+// are in a JSON body.
+func (c *ClientWithResponses) EditScriptProfileWithResponse(ctx context.Context, id int, body any, reqEditors ...RequestEditorFn) (*ScriptProfileResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support editing script profiles")
+	}
+
+	req, err := NewEditScriptProfileRequest(raw.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw.doScriptProfile(ctx, req, reqEditors)
+}
+
+// ArchiveScriptProfileWithResponse archives a script profile, so that it
+// no longer runs its script.
+func (c *ClientWithResponses) ArchiveScriptProfileWithResponse(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*ScriptProfileResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support archiving script profiles")
+	}
+
+	req, err := NewArchiveScriptProfileRequest(raw.Server, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw.doScriptProfile(ctx, req, reqEditors)
+}
+
+// ListScriptProfileActivitiesWithResponse lists the activities a script
+// profile created, starting at offset.
+func (c *ClientWithResponses) ListScriptProfileActivitiesWithResponse(ctx context.Context, id, offset int, reqEditors ...RequestEditorFn) (*ListScriptProfileActivitiesResponse, error) {
+	raw, ok := c.ClientInterface.(*Client)
+	if !ok {
+		return nil, fmt.Errorf("client doesn't support listing script profile activities")
+	}
+
+	req, err := NewListScriptProfileActivitiesRequest(raw.Server, id, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, body, err := raw.doJSON(ctx, req, reqEditors)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListScriptProfileActivitiesResponse{Body: body, HTTPResponse: rsp}
+	if isJSON200(rsp) {
+		var dest ActivityList
+		if err := decodeList(body, &dest, &dest.Results); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+	}
+
+	return response, nil
+}
+
+// doScriptProfile sends a request whose response is a script profile.
+func (c *Client) doScriptProfile(ctx context.Context, req *http.Request, reqEditors []RequestEditorFn) (*ScriptProfileResponse, error) {
+	rsp, body, err := c.doJSON(ctx, req, reqEditors)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ScriptProfileResponse{Body: body, HTTPResponse: rsp}
+	if isJSON200(rsp) && len(bytes.TrimSpace(body)) > 0 {
+		var dest ScriptProfileDetails
+		if err := json.Unmarshal(body, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+	}
+
+	return response, nil
+}
+
+// isJSON200 reports whether rsp is a successful JSON response.
+func isJSON200(rsp *http.Response) bool {
+	return strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode >= 200 && rsp.StatusCode <= 299
+}
+
+// decodeList decodes a page of results into page, or a plain list of them
+// into results when the server doesn't page them.
+func decodeList[T any](body []byte, page any, results *[]T) error {
+	if err := json.Unmarshal(body, page); err == nil {
+		return nil
+	}
+	return json.Unmarshal(body, results)
+}
+
+func offsetQuery(offset int) url.Values {
+	if offset <= 0 {
+		return nil
+	}
+	return url.Values{"offset": []string{strconv.Itoa(offset)}}
+}
+
+// ScriptProfileService creates and manages script profiles, which run
+// scripts on a schedule or when events happen on computers.
+type ScriptProfileService struct {
+	client *ClientWithResponses
+}
+
+// ScriptProfiles returns a ScriptProfileService that sends requests
+// through c.
+func (c *ClientWithResponses) ScriptProfiles() *ScriptProfileService {
+	return &ScriptProfileService{client: c}
+}
+
+// CreateScriptProfileParams are the parameters for creating a script
+// profile.
+type CreateScriptProfileParams struct {
+	Title    string
+	ScriptID int
+	Trigger  ScriptProfileTrigger
+	// Tags are the tags of the computers to run the script on. Either
+	// Tags or AllComputers must be set.
+	Tags         []string
+	AllComputers bool
+	// Username is the user to run the script as. Empty leaves the
+	// server's default.
+	Username string
+	// TimeLimit is the execution time limit in seconds. Zero leaves the
+	// server's default.
+	TimeLimit int
+}
+
+// EditScriptProfileParams are the parameters for editing a script profile.
+// Only non-nil fields are sent, leaving the others unchanged. The script
+// of a profile can't be changed.
+type EditScriptProfileParams struct {
+	Title        *string
+	Trigger      *ScriptProfileTrigger
+	Tags         *[]string
+	AllComputers *bool
+	Username     *string
+	TimeLimit    *int
+}
+
+// List returns every script profile, fetching as many pages as needed.
+func (s *ScriptProfileService) List(ctx context.Context) ([]ScriptProfileDetails, error) {
+	profiles := []ScriptProfileDetails{}
+
+	for {
+		list, err := ResponseValue[ScriptProfileList](s.client.ListScriptProfilesWithResponse(ctx, len(profiles)))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, list.Results...)
+
+		if !morePages(len(list.Results), len(profiles), list.Count, list.Next) {
+			return profiles, nil
+		}
+	}
+}
+
+// Get returns the script profile with the given ID.
+func (s *ScriptProfileService) Get(ctx context.Context, id int) (*ScriptProfileDetails, error) {
+	profile, err := ResponseValue[ScriptProfileDetails](s.client.GetScriptProfileWithResponse(ctx, id))
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Create creates a script profile and returns it.
+func (s *ScriptProfileService) Create(ctx context.Context, params CreateScriptProfileParams) (*ScriptProfileDetails, error) {
+	if params.Title == "" {
+		return nil, fmt.Errorf("script profile title must not be empty")
+	}
+	if params.ScriptID == 0 {
+		return nil, fmt.Errorf("script profile needs a script")
+	}
+	if err := params.Trigger.Validate(); err != nil {
+		return nil, err
+	}
+	if len(params.Tags) == 0 && !params.AllComputers {
+		return nil, fmt.Errorf("script profile needs tags or all computers to run on")
+	}
+
+	body := map[string]any{
+		"title":         params.Title,
+		"script_id":     params.ScriptID,
+		"trigger":       params.Trigger,
+		"tags":          nonNil(params.Tags),
+		"all_computers": params.AllComputers,
+	}
+	if params.Username != "" {
+		body["username"] = params.Username
+	}
+	if params.TimeLimit > 0 {
+		body["time_limit"] = params.TimeLimit
+	}
+
+	profile, err := ResponseValue[ScriptProfileDetails](s.client.CreateScriptProfileWithResponse(ctx, body))
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Edit changes the fields of the script profile with the given ID that are
+// set in params, and returns the edited profile.
+func (s *ScriptProfileService) Edit(ctx context.Context, id int, params EditScriptProfileParams) (*ScriptProfileDetails, error) {
+	body := map[string]any{}
+
+	if params.Title != nil {
+		body["title"] = *params.Title
+	}
+	if params.Trigger != nil {
+		if err := params.Trigger.Validate(); err != nil {
+			return nil, err
+		}
+		body["trigger"] = *params.Trigger
+	}
+	if params.Tags != nil {
+		body["tags"] = nonNil(*params.Tags)
+	}
+	if params.AllComputers != nil {
+		body["all_computers"] = *params.AllComputers
+	}
+	if params.Username != nil {
+		body["username"] = *params.Username
+	}
+	if params.TimeLimit != nil {
+		body["time_limit"] = *params.TimeLimit
+	}
+
+	if len(body) == 0 {
+		return nil, fmt.Errorf("no script profile fields to edit")
+	}
+
+	profile, err := ResponseValue[ScriptProfileDetails](s.client.EditScriptProfileWithResponse(ctx, id, body))
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Archive archives the script profile with the given ID, so that it no
+// longer runs its script.
+func (s *ScriptProfileService) Archive(ctx context.Context, id int) error {
+	return CheckResponse(s.client.ArchiveScriptProfileWithResponse(ctx, id))
+}
+
+// Activities returns the activities the script profile with the given ID
+// created each time it ran its script, fetching as many pages as needed.
+func (s *ScriptProfileService) Activities(ctx context.Context, id int) ([]Activity, error) {
+	activities := []Activity{}
+
+	for {
+		list, err := ResponseValue[ActivityList](s.client.ListScriptProfileActivitiesWithResponse(ctx, id, len(activities)))
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, list.Results...)
+
+		if !morePages(len(list.Results), len(activities), list.Count, list.Next) {
+			return activities, nil
+		}
+	}
+}
+
+// nonNil returns s, or an empty slice if it's nil, so that it's encoded as
+// [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
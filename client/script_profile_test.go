@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestScriptProfileService(t *testing.T) {
+	profile := map[string]any{
+		"id": 7, "title": "nightly", "script_id": 42, "all_computers": false, "tags": []string{"servers"},
+		"username": "root", "time_limit": 300,
+		"trigger": map[string]any{"trigger_type": "recurring", "interval": "0 3 * * *", "next_run": "2025-11-11T03:00:00Z"},
+	}
+
+	var (
+		lastMethod string
+		lastBody   map[string]any
+		archived   bool
+	)
+
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/script-profiles", func(w http.ResponseWriter, r *http.Request) {
+		lastMethod = r.Method
+		if r.Method == "POST" {
+			lastBody = nil
+			if err := json.NewDecoder(r.Body).Decode(&lastBody); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			writeJSON(w, profile)
+			return
+		}
+
+		if r.URL.Query().Get("offset") == "1" {
+			writeJSON(w, map[string]any{"count": 2, "results": []any{map[string]any{"id": 8, "title": "on enroll", "trigger": map[string]any{"trigger_type": "event", "event_type": "post_enrollment"}}}})
+			return
+		}
+		writeJSON(w, map[string]any{"count": 2, "results": []any{profile}, "next": "/api/script-profiles?offset=1"})
+	})
+	handler.HandleFunc("/api/script-profiles/7", func(w http.ResponseWriter, r *http.Request) {
+		lastMethod = r.Method
+		if r.Method == "PATCH" {
+			body, _ := io.ReadAll(r.Body)
+			lastBody = nil
+			if err := json.Unmarshal(body, &lastBody); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+		}
+		writeJSON(w, profile)
+	})
+	handler.HandleFunc("/api/script-profiles/7:archive", func(w http.ResponseWriter, r *http.Request) {
+		archived = r.Method == "POST"
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler.HandleFunc("/api/script-profiles/7/activities", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"id": 100, "computer_id": 1, "activity_status": "succeeded", "result_code": 0}})
+	})
+	handler.HandleFunc("/api/script-profiles/9", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "script profile not found"}`))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+	profiles := api.ScriptProfiles()
+
+	t.Run("list every page", func(t *testing.T) {
+		got, err := profiles.List(context.Background())
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(got) != 2 || got[1].Trigger.TriggerType != TriggerEvent || got[1].Trigger.EventType != EventPostEnrollment {
+			t.Fatalf("unexpected profiles %+v", got)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		got, err := profiles.Get(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if got.ScriptId != 42 || got.Trigger.Interval != "0 3 * * *" || *got.Trigger.NextRun != "2025-11-11T03:00:00Z" {
+			t.Fatalf("unexpected profile %+v", got)
+		}
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		if _, err := profiles.Get(context.Background(), 9); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		_, err := profiles.Create(context.Background(), CreateScriptProfileParams{
+			Title:    "nightly",
+			ScriptID: 42,
+			Trigger:  ScriptProfileTrigger{TriggerType: TriggerRecurring, Interval: "0 3 * * *"},
+			Tags:     []string{"servers"},
+			Username: "root",
+		})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		trigger, _ := lastBody["trigger"].(map[string]any)
+		if lastMethod != "POST" || lastBody["script_id"] != float64(42) || lastBody["username"] != "root" ||
+			trigger["interval"] != "0 3 * * *" || lastBody["all_computers"] != false {
+			t.Fatalf("unexpected body %v", lastBody)
+		}
+		if _, ok := lastBody["time_limit"]; ok {
+			t.Fatalf("expected no time limit, got %v", lastBody)
+		}
+	})
+
+	t.Run("create checks params", func(t *testing.T) {
+		for name, params := range map[string]CreateScriptProfileParams{
+			"no title":        {ScriptID: 1, AllComputers: true, Trigger: ScriptProfileTrigger{TriggerType: TriggerEvent, EventType: EventPostEnrollment}},
+			"no targets":      {Title: "a", ScriptID: 1, Trigger: ScriptProfileTrigger{TriggerType: TriggerEvent, EventType: EventPostEnrollment}},
+			"no interval":     {Title: "a", ScriptID: 1, AllComputers: true, Trigger: ScriptProfileTrigger{TriggerType: TriggerRecurring, Interval: " "}},
+			"no timestamp":    {Title: "a", ScriptID: 1, AllComputers: true, Trigger: ScriptProfileTrigger{TriggerType: TriggerOneTime}},
+			"unknown trigger": {Title: "a", ScriptID: 1, AllComputers: true, Trigger: ScriptProfileTrigger{TriggerType: "weekly"}},
+		} {
+			if _, err := profiles.Create(context.Background(), params); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+
+	t.Run("edit sends only set fields", func(t *testing.T) {
+		all := true
+		if _, err := profiles.Edit(context.Background(), 7, EditScriptProfileParams{AllComputers: &all}); err != nil {
+			t.Fatalf("Edit failed: %v", err)
+		}
+		if lastMethod != "PATCH" || len(lastBody) != 1 || lastBody["all_computers"] != true {
+			t.Fatalf("unexpected edit %s %v", lastMethod, lastBody)
+		}
+
+		if _, err := profiles.Edit(context.Background(), 7, EditScriptProfileParams{}); err == nil {
+			t.Fatal("expected an error without fields to edit")
+		}
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		if err := profiles.Archive(context.Background(), 7); err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+		if !archived {
+			t.Fatal("expected the profile to be archived")
+		}
+	})
+
+	t.Run("activities", func(t *testing.T) {
+		got, err := profiles.Activities(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("Activities failed: %v", err)
+		}
+		if len(got) != 1 || got[0].ActivityStatus != ActivitySucceeded {
+			t.Fatalf("unexpected activities %+v", got)
+		}
+	})
+}
+
+func TestScriptProfileListsStopPaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		profiles  map[string]any
+		wantCount int
+		wantPages int
+	}{
+		{
+			name:      "offset reaches the count",
+			profiles:  map[string]any{"count": 2, "next": "/next", "results": []map[string]any{{"id": 7}}},
+			wantCount: 2,
+			wantPages: 2,
+		},
+		{
+			name:      "empty page",
+			profiles:  map[string]any{"count": 10, "next": "/next", "results": []map[string]any{}},
+			wantPages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := 0
+
+			// The server ignores the offset, so it returns the same page
+			// however far the client has got.
+			servePage := func(w http.ResponseWriter, r *http.Request) {
+				pages++
+				if pages > 10 {
+					http.Error(w, "kept fetching pages", http.StatusBadRequest)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(tt.profiles)
+			}
+
+			handler := http.NewServeMux()
+			handler.HandleFunc("/api/script-profiles", servePage)
+			handler.HandleFunc("/api/script-profiles/7/activities", servePage)
+
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			api, err := NewClientWithResponses(server.URL)
+			if err != nil {
+				t.Fatalf("failed to init client: %v", err)
+			}
+
+			profiles, err := api.ScriptProfiles().List(context.Background())
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if len(profiles) != tt.wantCount || pages != tt.wantPages {
+				t.Fatalf("expected %d profiles in %d pages, got %d in %d", tt.wantCount, tt.wantPages, len(profiles), pages)
+			}
+
+			pages = 0
+			activities, err := api.ScriptProfiles().Activities(context.Background(), 7)
+			if err != nil {
+				t.Fatalf("Activities failed: %v", err)
+			}
+			if len(activities) != tt.wantCount || pages != tt.wantPages {
+				t.Fatalf("expected %d activities in %d pages, got %d in %d", tt.wantCount, tt.wantPages, len(activities), pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ScriptType selects whether a script is created as a legacy V1 script or
+// as a versioned V2 script.
+type ScriptType string
+
+const (
+	ScriptTypeV1 ScriptType = "V1"
+	ScriptTypeV2 ScriptType = "V2"
+)
+
+// DefaultMaxAttachmentSize is the default MaxAttachmentSize, 1 MiB.
+const DefaultMaxAttachmentSize = 1 << 20
+
+// MaxAttachmentSize is the largest script attachment, in bytes, that is
+// uploaded. It can be raised for servers that are configured to accept
+// larger attachments.
+var MaxAttachmentSize int64 = DefaultMaxAttachmentSize
+
+// ErrAttachmentTooLarge is returned for attachments larger than
+// MaxAttachmentSize, before they are uploaded.
+var ErrAttachmentTooLarge = errors.New("attachment too large")
+
+// ScriptService creates and manages scripts with typed parameters, hiding
+// the legacy actions and encoding rules behind them.
+type ScriptService struct {
+	client *ClientWithResponses
+}
+
+// Scripts returns a ScriptService that sends requests through c.
+func (c *ClientWithResponses) Scripts() *ScriptService {
+	return &ScriptService{client: c}
+}
+
+// CreateScriptParams are the parameters for creating a script.
+type CreateScriptParams struct {
+	Title string
+	// Code is the script's source. If it doesn't start with a shebang
+	// line, one is added for Interpreter.
+	Code        string
+	Interpreter string
+	// TimeLimit is the execution time limit in seconds. Zero leaves the
+	// server's default.
+	TimeLimit   int
+	Username    string
+	AccessGroup string
+	// ScriptType defaults to ScriptTypeV1 when empty.
+	ScriptType ScriptType
+}
+
+// EditScriptParams are the parameters for editing a script. Only non-nil
+// fields are sent, leaving the others unchanged.
+type EditScriptParams struct {
+	Title *string
+	// Code replaces the script's source. If it doesn't start with a
+	// shebang line, one is added for Interpreter.
+	Code *string
+	// Interpreter changes the interpreter the script is run with. Without
+	// Code, the script's current code is fetched and sent again with a new
+	// shebang line, since the interpreter is part of the code.
+	Interpreter *string
+	TimeLimit   *int
+	Username    *string
+	AccessGroup *string
+}
+
+// ExecuteScriptParams are the parameters for running a script.
+type ExecuteScriptParams struct {
+	// Query selects the computers to run the script on, such as
+	// "tag:servers" or "id:12".
+	Query string
+	// Username is the user to run the script as. Empty leaves the
+	// script's own user.
+	Username string
+	// TimeLimit is the execution time limit in seconds. Zero leaves the
+	// script's own limit.
+	TimeLimit int
+}
+
+// Create creates a script and returns it.
+func (s *ScriptService) Create(ctx context.Context, params CreateScriptParams) (Script, error) {
+	if params.Title == "" {
+		return nil, fmt.Errorf("script title must not be empty")
+	}
+
+	scriptType := params.ScriptType
+	if scriptType == "" {
+		scriptType = ScriptTypeV1
+	}
+
+	values := url.Values{
+		"title":       []string{params.Title},
+		"code":        []string{encodeScriptCode(params.Code, params.Interpreter)},
+		"script_type": []string{string(scriptType)},
+	}
+	if params.TimeLimit > 0 {
+		values.Set("time_limit", strconv.Itoa(params.TimeLimit))
+	}
+	if params.Username != "" {
+		values.Set("username", params.Username)
+	}
+	if params.AccessGroup != "" {
+		values.Set("access_group", params.AccessGroup)
+	}
+
+	res, err := s.invoke(ctx, "CreateScript", values)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.AsScript()
+}
+
+// Edit changes the fields of the script with the given ID that are set in
+// params, and returns the edited script.
+func (s *ScriptService) Edit(ctx context.Context, id int, params EditScriptParams) (Script, error) {
+	values := url.Values{
+		"script_id": []string{strconv.Itoa(id)},
+	}
+
+	if params.Title != nil {
+		values.Set("title", *params.Title)
+	}
+	if params.Code != nil {
+		values.Set("code", encodeScriptCode(*params.Code, deref(params.Interpreter)))
+	} else if params.Interpreter != nil {
+		if *params.Interpreter == "" {
+			return nil, fmt.Errorf("script interpreter must not be empty")
+		}
+
+		code, err := s.GetCode(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the script's code to change its interpreter: %w", err)
+		}
+		values.Set("code", encodeScriptCode(SetShebang(code, *params.Interpreter), ""))
+	}
+	if params.TimeLimit != nil {
+		values.Set("time_limit", strconv.Itoa(*params.TimeLimit))
+	}
+	if params.Username != nil {
+		values.Set("username", *params.Username)
+	}
+	if params.AccessGroup != nil {
+		values.Set("access_group", *params.AccessGroup)
+	}
+
+	res, err := s.invoke(ctx, "EditScript", values)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.AsScript()
+}
+
+// AddAttachment attaches a file with the given name and contents to a
+// script, and returns the attachment's filename.
+func (s *ScriptService) AddAttachment(ctx context.Context, scriptID int, filename string, contents []byte) (string, error) {
+	if filename == "" || strings.Contains(filename, "$$") {
+		return "", fmt.Errorf("invalid attachment filename: %q", filename)
+	}
+	if int64(len(contents)) > MaxAttachmentSize {
+		return "", fmt.Errorf("%w: %s is %d bytes, the limit is %d bytes", ErrAttachmentTooLarge, filename, len(contents), MaxAttachmentSize)
+	}
+
+	res, err := s.invoke(ctx, "CreateScriptAttachment", url.Values{
+		"script_id": []string{strconv.Itoa(scriptID)},
+		"file":      []string{filename + "$$" + base64.StdEncoding.EncodeToString(contents)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return res.AsLegacyScriptAttachment()
+}
+
+// ListAttachments returns the attachments of the script with the given
+// ID. V1 scripts only report attachment filenames, so their IDs are looked
+// up by listing the script's attachments.
+func (s *ScriptService) ListAttachments(ctx context.Context, scriptID int) ([]ScriptAttachment, error) {
+	script, err := s.Get(ctx, scriptID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := script.GetAttachments()
+	if attachments == nil {
+		attachments = []ScriptAttachment{}
+	}
+	if _, ok := script.(*V1Script); !ok || len(attachments) == 0 {
+		return attachments, nil
+	}
+
+	listed, err := ResponseValue[[]ScriptAttachment](s.client.ListScriptAttachmentsWithResponse(ctx, scriptID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the attachments of script %d: %w", scriptID, err)
+	}
+	for i, a := range attachments {
+		j := slices.IndexFunc(listed, func(l ScriptAttachment) bool { return l.Filename == a.Filename })
+		if j < 0 || listed[j].Id == 0 {
+			return nil, fmt.Errorf("attachment %s of script %d isn't listed with an ID", a.Filename, scriptID)
+		}
+		attachments[i].Id = listed[j].Id
+	}
+
+	return attachments, nil
+}
+
+// DownloadAttachment writes the contents of a script's attachment to w as
+// they are received, and returns the attachment's filename.
+func (s *ScriptService) DownloadAttachment(ctx context.Context, scriptID, attachmentID int, w io.Writer) (string, error) {
+	resp, err := s.client.GetScriptAttachment(ctx, scriptID, attachmentID)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", NewAPIError(resp, body)
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		// The contents are sent as a JSON string, which has to be decoded
+		// as a whole.
+		var contents ScriptAttachmentResult
+		if err := json.NewDecoder(resp.Body).Decode(&contents); err != nil {
+			return "", fmt.Errorf("failed to decode attachment: %w", err)
+		}
+		if _, err := io.WriteString(w, contents); err != nil {
+			return "", err
+		}
+	} else if _, err := io.Copy(w, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to download attachment: %w", err)
+	}
+
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return params["filename"], nil
+	}
+
+	attachments, err := s.ListAttachments(ctx, scriptID)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range attachments {
+		if a.Id == attachmentID {
+			return a.Filename, nil
+		}
+	}
+
+	return "", fmt.Errorf("attachment %d not found in script %d", attachmentID, scriptID)
+}
+
+// RemoveAttachment removes the attachment with the given filename from a
+// script.
+func (s *ScriptService) RemoveAttachment(ctx context.Context, scriptID int, filename string) error {
+	if filename == "" {
+		return fmt.Errorf("attachment filename must not be empty")
+	}
+
+	return CheckResponse(s.client.InvokeLegacyActionWithResponse(ctx, LegacyActionParams("RemoveScriptAttachment"), EncodeQueryRequestEditor(url.Values{
+		"script_id": []string{strconv.Itoa(scriptID)},
+		"filename":  []string{filename},
+	})))
+}
+
+// Get returns the script with the given ID.
+func (s *ScriptService) Get(ctx context.Context, id int) (Script, error) {
+	res, err := ResponseValue[ScriptResult](s.client.GetScriptWithResponse(ctx, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.AsScript()
+}
+
+// GetCode returns the code of the script with the given ID, including its
+// shebang line.
+func (s *ScriptService) GetCode(ctx context.Context, id int) (string, error) {
+	res, err := s.invoke(ctx, "GetScriptCode", url.Values{
+		"script_id": []string{strconv.Itoa(id)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return res.AsLegacyScriptCode()
+}
+
+// Execute runs the script with the given ID on the computers matching
+// params.Query, and returns the parent activity of the runs. Use
+// ActivityService.Wait to wait for their results.
+func (s *ScriptService) Execute(ctx context.Context, id int, params ExecuteScriptParams) (*Activity, error) {
+	if params.Query == "" {
+		return nil, fmt.Errorf("query must not be empty")
+	}
+
+	values := url.Values{
+		"script_id": []string{strconv.Itoa(id)},
+		"query":     []string{params.Query},
+	}
+	if params.Username != "" {
+		values.Set("username", params.Username)
+	}
+	if params.TimeLimit > 0 {
+		values.Set("time_limit", strconv.Itoa(params.TimeLimit))
+	}
+
+	res, err := s.invoke(ctx, "ExecuteScript", values)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.AsActivity()
+}
+
+// Archive archives the V2 script with the given ID.
+func (s *ScriptService) Archive(ctx context.Context, id int) error {
+	return CheckResponse(s.client.ArchiveScriptWithResponse(ctx, id))
+}
+
+// Redact permanently removes the code and attachments of the V2 script
+// with the given ID. This can't be undone.
+func (s *ScriptService) Redact(ctx context.Context, id int) error {
+	return CheckResponse(s.client.RedactScriptWithResponse(ctx, id))
+}
+
+func (s *ScriptService) invoke(ctx context.Context, action string, values url.Values) (LegacyActionResponse, error) {
+	return ResponseValue[LegacyActionResponse](
+		s.client.InvokeLegacyActionWithResponse(ctx, LegacyActionParams(action), EncodeQueryRequestEditor(values)),
+	)
+}
+
+// ShebangInterpreter returns the interpreter named by the shebang line of
+// code, such as /bin/bash, or an empty string if code has no shebang.
+func ShebangInterpreter(code string) string {
+	if !strings.HasPrefix(code, "#!") {
+		return ""
+	}
+
+	line, _, _ := strings.Cut(code[2:], "\n")
+	return strings.TrimSpace(line)
+}
+
+// SetShebang returns code with its shebang line naming interpreter,
+// replacing the existing one or adding one if there is none. An empty
+// interpreter leaves code as it is.
+func SetShebang(code, interpreter string) string {
+	if interpreter == "" {
+		return code
+	}
+
+	if strings.HasPrefix(code, "#!") {
+		_, rest, _ := strings.Cut(code, "\n")
+		code = rest
+	}
+
+	return "#!" + interpreter + "\n" + code
+}
+
+// encodeScriptCode base64 encodes code as the legacy API expects, adding a
+// shebang line for interpreter if the code doesn't have one.
+func encodeScriptCode(code, interpreter string) string {
+	if interpreter != "" && !strings.HasPrefix(code, "#!") {
+		code = "#!" + interpreter + "\n" + code
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(code))
+}
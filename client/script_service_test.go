@@ -0,0 +1,307 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestScriptService(t *testing.T) {
+	var lastQuery url.Values
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var resp any
+		switch lastQuery.Get("action") {
+		case "CreateScript":
+			if lastQuery.Get("script_type") == "V2" {
+				resp = map[string]any{"id": 42, "title": lastQuery.Get("title"), "status": "ACTIVE", "version_number": 1}
+			} else {
+				resp = map[string]any{"id": 43, "title": lastQuery.Get("title"), "status": "V1"}
+			}
+		case "EditScript":
+			resp = map[string]any{"id": 42, "title": "edited", "status": "ACTIVE", "version_number": 2}
+		case "GetScriptCode":
+			resp = "#!/bin/sh\necho hello"
+		case "CreateScriptAttachment":
+			resp = "note.txt"
+		case "RemoveScriptAttachment":
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			resp = map[string]any{"message": "unknown action"}
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/42", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{
+			"id": 42, "title": "fetched", "status": "ARCHIVED", "version_number": 3,
+			"attachments": []map[string]any{{"id": 7, "filename": "note.txt"}},
+		}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/42/attachments/7", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode("foo"); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/42/attachments/8", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="data.bin"`)
+		w.Write([]byte{0, 1, 2})
+	})
+	handler.HandleFunc("/api/scripts/42:archive", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler.HandleFunc("/api/scripts/42:redact", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "script not found"}`))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+	scripts := api.Scripts()
+
+	t.Run("create V2", func(t *testing.T) {
+		script, err := scripts.Create(context.Background(), CreateScriptParams{
+			Title:       "hello",
+			Code:        "echo hello",
+			Interpreter: "/bin/bash",
+			TimeLimit:   60,
+			Username:    "ubuntu",
+			AccessGroup: "global",
+			ScriptType:  ScriptTypeV2,
+		})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		if _, ok := script.(*V2Script); !ok || script.GetID() != 42 {
+			t.Fatalf("unexpected script %T %+v", script, script)
+		}
+
+		code, err := base64.StdEncoding.DecodeString(lastQuery.Get("code"))
+		if err != nil {
+			t.Fatalf("code isn't base64 encoded: %v", err)
+		}
+		if string(code) != "#!/bin/bash\necho hello" {
+			t.Fatalf("unexpected code %q", code)
+		}
+		if lastQuery.Get("time_limit") != "60" || lastQuery.Get("username") != "ubuntu" || lastQuery.Get("access_group") != "global" {
+			t.Fatalf("unexpected query %v", lastQuery)
+		}
+	})
+
+	t.Run("create defaults to V1", func(t *testing.T) {
+		script, err := scripts.Create(context.Background(), CreateScriptParams{Title: "legacy", Code: "#!/bin/sh\ntrue"})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		if _, ok := script.(*V1Script); !ok {
+			t.Fatalf("expected *V1Script, got %T", script)
+		}
+		if lastQuery.Get("script_type") != "V1" || lastQuery.Has("time_limit") || lastQuery.Has("username") {
+			t.Fatalf("unexpected query %v", lastQuery)
+		}
+	})
+
+	t.Run("edit sends only set fields", func(t *testing.T) {
+		timeLimit := 120
+		script, err := scripts.Edit(context.Background(), 42, EditScriptParams{TimeLimit: &timeLimit})
+		if err != nil {
+			t.Fatalf("Edit failed: %v", err)
+		}
+
+		if script.GetVersion() != 2 {
+			t.Fatalf("unexpected script %+v", script)
+		}
+		if lastQuery.Get("script_id") != "42" || lastQuery.Get("time_limit") != "120" {
+			t.Fatalf("unexpected query %v", lastQuery)
+		}
+		for _, key := range []string{"title", "code", "username", "access_group"} {
+			if lastQuery.Has(key) {
+				t.Fatalf("expected %s not to be sent, got %v", key, lastQuery)
+			}
+		}
+	})
+
+	t.Run("edit interpreter only", func(t *testing.T) {
+		interpreter := "/bin/bash"
+		if _, err := scripts.Edit(context.Background(), 42, EditScriptParams{Interpreter: &interpreter}); err != nil {
+			t.Fatalf("Edit failed: %v", err)
+		}
+
+		code, err := base64.StdEncoding.DecodeString(lastQuery.Get("code"))
+		if err != nil {
+			t.Fatalf("code isn't base64 encoded: %v", err)
+		}
+		if string(code) != "#!/bin/bash\necho hello" {
+			t.Fatalf("unexpected code %q", code)
+		}
+	})
+
+	t.Run("get code", func(t *testing.T) {
+		code, err := scripts.GetCode(context.Background(), 42)
+		if err != nil {
+			t.Fatalf("GetCode failed: %v", err)
+		}
+
+		if code != "#!/bin/sh\necho hello" || lastQuery.Get("script_id") != "42" {
+			t.Fatalf("unexpected code %q, query %v", code, lastQuery)
+		}
+	})
+
+	t.Run("add attachment", func(t *testing.T) {
+		filename, err := scripts.AddAttachment(context.Background(), 42, "note.txt", []byte("foo"))
+		if err != nil {
+			t.Fatalf("AddAttachment failed: %v", err)
+		}
+
+		if filename != "note.txt" || lastQuery.Get("file") != "note.txt$$Zm9v" {
+			t.Fatalf("unexpected attachment %q, query %v", filename, lastQuery)
+		}
+	})
+
+	t.Run("attachment too large", func(t *testing.T) {
+		lastQuery = nil
+
+		_, err := scripts.AddAttachment(context.Background(), 42, "big.bin", make([]byte, MaxAttachmentSize+1))
+		if !errors.Is(err, ErrAttachmentTooLarge) {
+			t.Fatalf("expected ErrAttachmentTooLarge, got %v", err)
+		}
+		if lastQuery != nil {
+			t.Fatalf("expected attachment not to be uploaded, got query %v", lastQuery)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		script, err := scripts.Get(context.Background(), 42)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+
+		if script.GetStatus() != "ARCHIVED" || script.GetTitle() != "fetched" {
+			t.Fatalf("unexpected script %+v", script)
+		}
+	})
+
+	t.Run("list attachments", func(t *testing.T) {
+		attachments, err := scripts.ListAttachments(context.Background(), 42)
+		if err != nil {
+			t.Fatalf("ListAttachments failed: %v", err)
+		}
+
+		if len(attachments) != 1 || attachments[0].Id != 7 || attachments[0].Filename != "note.txt" {
+			t.Fatalf("unexpected attachments %+v", attachments)
+		}
+	})
+
+	t.Run("download JSON attachment", func(t *testing.T) {
+		var buf bytes.Buffer
+		filename, err := scripts.DownloadAttachment(context.Background(), 42, 7, &buf)
+		if err != nil {
+			t.Fatalf("DownloadAttachment failed: %v", err)
+		}
+
+		if filename != "note.txt" || buf.String() != "foo" {
+			t.Fatalf("unexpected attachment %q: %q", filename, buf.String())
+		}
+	})
+
+	t.Run("download raw attachment", func(t *testing.T) {
+		var buf bytes.Buffer
+		filename, err := scripts.DownloadAttachment(context.Background(), 42, 8, &buf)
+		if err != nil {
+			t.Fatalf("DownloadAttachment failed: %v", err)
+		}
+
+		if filename != "data.bin" || !bytes.Equal(buf.Bytes(), []byte{0, 1, 2}) {
+			t.Fatalf("unexpected attachment %q: %v", filename, buf.Bytes())
+		}
+	})
+
+	t.Run("download missing attachment", func(t *testing.T) {
+		if _, err := scripts.DownloadAttachment(context.Background(), 42, 9, io.Discard); !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("remove attachment", func(t *testing.T) {
+		if err := scripts.RemoveAttachment(context.Background(), 42, "note.txt"); err != nil {
+			t.Fatalf("RemoveAttachment failed: %v", err)
+		}
+
+		if lastQuery.Get("action") != "RemoveScriptAttachment" || lastQuery.Get("script_id") != "42" || lastQuery.Get("filename") != "note.txt" {
+			t.Fatalf("unexpected query %v", lastQuery)
+		}
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		if err := scripts.Archive(context.Background(), 42); err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+	})
+
+	t.Run("redact error", func(t *testing.T) {
+		if err := scripts.Redact(context.Background(), 42); !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestSetShebang(t *testing.T) {
+	tests := []struct {
+		code, interpreter, want string
+	}{
+		{"echo hi", "/bin/bash", "#!/bin/bash\necho hi"},
+		{"#!/bin/sh\necho hi", "/bin/bash", "#!/bin/bash\necho hi"},
+		{"#!/bin/sh", "/bin/bash", "#!/bin/bash\n"},
+		{"#!/bin/sh\necho hi", "", "#!/bin/sh\necho hi"},
+	}
+
+	for _, tt := range tests {
+		if got := SetShebang(tt.code, tt.interpreter); got != tt.want {
+			t.Errorf("SetShebang(%q, %q) = %q, want %q", tt.code, tt.interpreter, got, tt.want)
+		}
+	}
+}
+
+func TestShebangInterpreter(t *testing.T) {
+	tests := map[string]string{
+		"#!/bin/bash\necho hello":              "/bin/bash",
+		"#! /usr/bin/env python3\nprint('hi')": "/usr/bin/env python3",
+		"#!/bin/sh":                            "/bin/sh",
+		"echo hello":                           "",
+	}
+
+	for code, want := range tests {
+		if got := ShebangInterpreter(code); got != want {
+			t.Errorf("ShebangInterpreter(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
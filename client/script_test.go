@@ -0,0 +1,228 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestScriptResultAsScript(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		wantV2      bool
+		wantStatus  string
+		wantVersion int
+		wantAttach  []ScriptAttachment
+	}{
+		{
+			name:       "V1",
+			payload:    `{"id": 1, "title": "legacy", "status": "V1", "time_limit": 300, "username": "root", "access_group": "global", "attachments": ["note.txt"]}`,
+			wantStatus: "V1",
+			wantAttach: []ScriptAttachment{{Filename: "note.txt"}},
+		},
+		{
+			name:        "ACTIVE",
+			payload:     `{"id": 1, "title": "legacy", "status": "ACTIVE", "version_number": 2, "time_limit": 300, "username": "root", "access_group": "global", "attachments": [{"id": 7, "filename": "note.txt"}]}`,
+			wantV2:      true,
+			wantStatus:  "ACTIVE",
+			wantVersion: 2,
+			wantAttach:  []ScriptAttachment{{Id: 7, Filename: "note.txt"}},
+		},
+		{
+			name:        "ARCHIVED",
+			payload:     `{"id": 1, "title": "legacy", "status": "ARCHIVED", "version_number": 3, "time_limit": 300, "username": "root", "access_group": "global", "attachments": []}`,
+			wantV2:      true,
+			wantStatus:  "ARCHIVED",
+			wantVersion: 3,
+			wantAttach:  []ScriptAttachment{},
+		},
+		{
+			name:        "REDACTED",
+			payload:     `{"id": 1, "title": "legacy", "status": "REDACTED", "version_number": 1, "time_limit": 300, "username": "root", "access_group": "global", "attachments": null}`,
+			wantV2:      true,
+			wantStatus:  "REDACTED",
+			wantVersion: 1,
+		},
+		{
+			name:        "missing status with V2 fields",
+			payload:     `{"id": 1, "title": "legacy", "version_number": 4, "time_limit": 300, "username": "root", "access_group": "global"}`,
+			wantV2:      true,
+			wantVersion: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result ScriptResult
+			if err := json.Unmarshal([]byte(tt.payload), &result); err != nil {
+				t.Fatalf("failed to unmarshal payload: %v", err)
+			}
+
+			script, err := result.AsScript()
+			if err != nil {
+				t.Fatalf("AsScript failed: %v", err)
+			}
+
+			switch script.(type) {
+			case *V2Script:
+				if !tt.wantV2 {
+					t.Fatalf("expected *V1Script, got %T", script)
+				}
+			case *V1Script:
+				if tt.wantV2 {
+					t.Fatalf("expected *V2Script, got %T", script)
+				}
+			}
+
+			if script.GetID() != 1 || script.GetTitle() != "legacy" {
+				t.Fatalf("unexpected id/title: %d %q", script.GetID(), script.GetTitle())
+			}
+			if script.GetTimeLimit() != 300 || script.GetUsername() != "root" || script.GetAccessGroup() != "global" {
+				t.Fatalf("unexpected time limit/username/access group: %d %q %q", script.GetTimeLimit(), script.GetUsername(), script.GetAccessGroup())
+			}
+			if script.GetStatus() != tt.wantStatus {
+				t.Fatalf("expected status %q, got %q", tt.wantStatus, script.GetStatus())
+			}
+			if script.GetVersion() != tt.wantVersion {
+				t.Fatalf("expected version %d, got %d", tt.wantVersion, script.GetVersion())
+			}
+
+			attachments := script.GetAttachments()
+			if len(attachments) != len(tt.wantAttach) {
+				t.Fatalf("expected attachments %+v, got %+v", tt.wantAttach, attachments)
+			}
+			for i := range attachments {
+				if attachments[i] != tt.wantAttach[i] {
+					t.Fatalf("expected attachments %+v, got %+v", tt.wantAttach, attachments)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown status", func(t *testing.T) {
+		var result ScriptResult
+		if err := json.Unmarshal([]byte(`{"id": 1, "status": "DELETED"}`), &result); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+
+		if _, err := result.AsScript(); err == nil {
+			t.Fatal("expected error for unknown status")
+		}
+	})
+
+	t.Run("legacy action response", func(t *testing.T) {
+		var resp LegacyActionResponse
+		if err := json.Unmarshal([]byte(`{"id": 42, "title": "created", "status": "ACTIVE", "version_number": 1}`), &resp); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+
+		script, err := resp.AsScript()
+		if err != nil {
+			t.Fatalf("AsScript failed: %v", err)
+		}
+
+		v2, ok := script.(*V2Script)
+		if !ok {
+			t.Fatalf("expected *V2Script, got %T", script)
+		}
+		if v2.Id != 42 || v2.Title != "created" {
+			t.Fatalf("unexpected script: %+v", v2)
+		}
+	})
+}
+
+func TestScriptResultValueByDiscriminator(t *testing.T) {
+	tests := []struct {
+		name              string
+		payload           string
+		wantDiscriminator string
+		wantV2            bool
+	}{
+		{
+			name:              "V1",
+			payload:           `{"id": 1, "title": "legacy", "status": "V1", "attachments": ["note.txt"]}`,
+			wantDiscriminator: "V1",
+		},
+		{
+			name:              "ACTIVE",
+			payload:           `{"id": 1, "title": "legacy", "status": "ACTIVE", "version_number": 2}`,
+			wantDiscriminator: "ACTIVE",
+			wantV2:            true,
+		},
+		{
+			name:              "ARCHIVED",
+			payload:           `{"id": 1, "title": "legacy", "status": "ARCHIVED", "version_number": 3}`,
+			wantDiscriminator: "ARCHIVED",
+			wantV2:            true,
+		},
+		{
+			name:              "REDACTED",
+			payload:           `{"id": 1, "title": "legacy", "status": "REDACTED", "version_number": 1}`,
+			wantDiscriminator: "REDACTED",
+			wantV2:            true,
+		},
+		{
+			name:              "missing status with V2 fields",
+			payload:           `{"id": 1, "title": "legacy", "version_number": 4}`,
+			wantDiscriminator: "ACTIVE",
+			wantV2:            true,
+		},
+		{
+			name:              "missing status without V2 fields",
+			payload:           `{"id": 1, "title": "legacy"}`,
+			wantDiscriminator: "V1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result ScriptResult
+			if err := json.Unmarshal([]byte(tt.payload), &result); err != nil {
+				t.Fatalf("failed to unmarshal payload: %v", err)
+			}
+
+			discriminator, err := result.Discriminator()
+			if err != nil {
+				t.Fatalf("Discriminator failed: %v", err)
+			}
+			if discriminator != tt.wantDiscriminator {
+				t.Fatalf("expected discriminator %q, got %q", tt.wantDiscriminator, discriminator)
+			}
+
+			value, err := result.ValueByDiscriminator()
+			if err != nil {
+				t.Fatalf("ValueByDiscriminator failed: %v", err)
+			}
+
+			switch script := value.(type) {
+			case V2Script:
+				if !tt.wantV2 {
+					t.Fatalf("expected V1Script, got %T", value)
+				}
+				if script.Id != 1 || script.Title != "legacy" {
+					t.Fatalf("unexpected script: %+v", script)
+				}
+			case V1Script:
+				if tt.wantV2 {
+					t.Fatalf("expected V2Script, got %T", value)
+				}
+				if script.Id != 1 || script.Title != "legacy" {
+					t.Fatalf("unexpected script: %+v", script)
+				}
+			default:
+				t.Fatalf("unexpected value %T", value)
+			}
+		})
+	}
+
+	t.Run("unknown status", func(t *testing.T) {
+		var result ScriptResult
+		if err := json.Unmarshal([]byte(`{"id": 1, "status": "V2Script"}`), &result); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+
+		if _, err := result.ValueByDiscriminator(); err == nil {
+			t.Fatal("expected error for unknown status")
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestScriptVersions(t *testing.T) {
+	versions := []map[string]any{
+		{"version_number": 1, "title": "backup", "code": "tar c /srv", "interpreter": "/bin/sh", "time_limit": 300,
+			"last_edited_by": map[string]any{"id": 1, "name": "Jan"}, "last_edited_at": "2025-11-10T02:56:25"},
+		{"version_number": 2, "title": "backup", "code": "tar c /srv /etc", "interpreter": "/bin/bash", "time_limit": 600},
+		{"version_number": 3, "title": "backups", "code": "#!/bin/bash\ntar c /"},
+	}
+
+	var (
+		edited url.Values
+		pages  int
+	)
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/scripts/42/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Two versions per page.
+		resp := map[string]any{"count": len(versions), "results": versions[:2], "next": "/api/scripts/42/versions?offset=2"}
+		if r.URL.Query().Get("offset") == "2" {
+			resp = map[string]any{"count": len(versions), "results": versions[2:]}
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/43/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(versions[:1]); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/44/versions", func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		if pages > 10 {
+			http.Error(w, "kept fetching pages", http.StatusBadRequest)
+			return
+		}
+
+		// The offset is ignored, so every page is the first one.
+		w.Header().Set("Content-Type", "application/json")
+		resp := map[string]any{"count": len(versions), "results": versions[:2], "next": "/api/scripts/44/versions?offset=2"}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/45/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		resp := map[string]any{"results": []any{}, "next": "/api/scripts/45/versions?offset=0"}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/42/versions/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(versions[0]); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+	handler.HandleFunc("/api/scripts/42/versions/9", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "version not found"}`))
+	})
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		edited = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"id": 42, "title": "backup", "status": "ACTIVE", "version_number": 4}); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	t.Run("list every page", func(t *testing.T) {
+		got, err := api.Scripts().Versions(context.Background(), 42)
+		if err != nil {
+			t.Fatalf("Versions failed: %v", err)
+		}
+
+		if len(got) != 3 || got[2].VersionNumber != 3 {
+			t.Fatalf("unexpected versions %+v", got)
+		}
+		if *got[0].LastEditedBy.Name != "Jan" || *got[0].LastEditedAt != "2025-11-10T02:56:25" {
+			t.Fatalf("unexpected editor %+v", got[0])
+		}
+	})
+
+	t.Run("list without paging", func(t *testing.T) {
+		got, err := api.Scripts().Versions(context.Background(), 43)
+		if err != nil {
+			t.Fatalf("Versions failed: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("unexpected versions %+v", got)
+		}
+	})
+
+	t.Run("stop at the count", func(t *testing.T) {
+		pages = 0
+		if _, err := api.Scripts().Versions(context.Background(), 44); err != nil {
+			t.Fatalf("Versions failed: %v", err)
+		}
+		if pages != 2 {
+			t.Fatalf("expected 2 pages, got %d", pages)
+		}
+	})
+
+	t.Run("stop at an empty page", func(t *testing.T) {
+		got, err := api.Scripts().Versions(context.Background(), 45)
+		if err != nil {
+			t.Fatalf("Versions failed: %v", err)
+		}
+		if len(got) != 0 {
+			t.Fatalf("unexpected versions %+v", got)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		v, err := api.Scripts().Version(context.Background(), 42, 1)
+		if err != nil {
+			t.Fatalf("Version failed: %v", err)
+		}
+		if v.Source() != "#!/bin/sh\ntar c /srv" {
+			t.Fatalf("unexpected source %q", v.Source())
+		}
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		if _, err := api.Scripts().Version(context.Background(), 42, 9); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		script, err := api.Scripts().Revert(context.Background(), 42, 1)
+		if err != nil {
+			t.Fatalf("Revert failed: %v", err)
+		}
+		if script.GetVersion() != 4 {
+			t.Fatalf("expected a new version, got %d", script.GetVersion())
+		}
+
+		code, err := base64.StdEncoding.DecodeString(edited.Get("code"))
+		if err != nil {
+			t.Fatalf("code isn't base64 encoded: %v", err)
+		}
+		if edited.Get("action") != "EditScript" || string(code) != "#!/bin/sh\ntar c /srv" ||
+			edited.Get("title") != "backup" || edited.Get("time_limit") != "300" || edited.Has("username") {
+			t.Fatalf("unexpected edit %v (code %q)", edited, code)
+		}
+	})
+}
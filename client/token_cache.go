@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedToken is a token kept in a TokenCache, along with the accounts
+// that were reported when it was issued.
+type CachedToken struct {
+	Token          string         `json:"token"`
+	CurrentAccount string         `json:"current_account,omitempty"`
+	Accounts       []LoginAccount `json:"accounts,omitempty"`
+	// IssuedAt is when the token was issued, which decides when a token
+	// without an exp claim expires. Such a token without IssuedAt is
+	// treated as expired.
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// TokenCache keeps tokens between clients, so that a new client can reuse
+// a token instead of logging in again. Load returns nil if there is no
+// token for key.
+type TokenCache interface {
+	Load(key string) (*CachedToken, error)
+	Store(key string, token *CachedToken) error
+	Delete(key string) error
+}
+
+// TokenCacheKey returns a cache key for the token issued to identity, such
+// as an email or access key, logging in with secret, such as a password
+// or secret key, for account on the Landscape at baseURL. The parts are
+// hashed so that the key doesn't reveal them. Since the secret is part of
+// the key, a token is only reused by someone who knows the secret it was
+// issued for, and not at all once the secret has changed.
+func TokenCacheKey(baseURL, account, identity, secret string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{strings.TrimRight(baseURL, "/"), account, identity, secret}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// WithTokenCache reuses the token stored in cache under key, as long as
+// it isn't about to expire, instead of logging in. New tokens are stored
+// in cache after every login, and the stored token is removed when the
+// server rejects it.
+func WithTokenCache(cache TokenCache, key string) Option {
+	return func(o *options) error {
+		o.tokenCache = cache
+		o.tokenCacheKey = key
+		return nil
+	}
+}
+
+// WithAccount switches to account after every login. Unlike calling
+// SwitchAccount on the client, the account is selected before the token
+// is stored with WithTokenCache.
+func WithAccount(account string) Option {
+	return func(o *options) error {
+		o.account = account
+		return nil
+	}
+}
+
+// FileTokenCache is a TokenCache stored as a JSON file that only the user
+// can read.
+type FileTokenCache struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenCache returns a FileTokenCache stored at path. The file is
+// created when the first token is stored.
+func NewFileTokenCache(path string) *FileTokenCache {
+	return &FileTokenCache{path: path}
+}
+
+// Load implements TokenCache for FileTokenCache.
+func (c *FileTokenCache) Load(key string) (*CachedToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tokens, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens[key], nil
+}
+
+// Store implements TokenCache for FileTokenCache.
+func (c *FileTokenCache) Store(key string, token *CachedToken) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tokens, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	tokens[key] = token
+	return c.write(tokens)
+}
+
+// Delete implements TokenCache for FileTokenCache.
+func (c *FileTokenCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tokens, err := c.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+
+	delete(tokens, key)
+	return c.write(tokens)
+}
+
+// Clear removes the cache file, and with it every token.
+func (c *FileTokenCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove token cache: %w", err)
+	}
+
+	return nil
+}
+
+func (c *FileTokenCache) read() (map[string]*CachedToken, error) {
+	tokens := map[string]*CachedToken{}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token cache %s: %w", c.path, err)
+	}
+
+	return tokens, nil
+}
+
+func (c *FileTokenCache) write(tokens map[string]*CachedToken) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create token cache directory: %w", err)
+	}
+
+	// The tokens are written to a temporary file that replaces the cache
+	// once it's complete, so that other processes reading or writing the
+	// cache at the same time never see it half written.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".tokens-*")
+	if err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+
+	return nil
+}
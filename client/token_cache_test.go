@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenCache(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+
+	var rejectedToken atomic.Value
+	rejected := func(token string) bool {
+		r, _ := rejectedToken.Load().(string)
+		return token == r
+	}
+
+	server, logins := newAuthTestServer(t, func(int) time.Time { return exp }, rejected)
+
+	path := filepath.Join(t.TempDir(), "landscape", "tokens.json")
+	cache := NewFileTokenCache(path)
+	key := TokenCacheKey(server.URL, "", "ak", "sk")
+
+	newClient := func() *ClientWithResponses {
+		t.Helper()
+
+		api, err := NewLandscapeAPIClientWithContext(context.Background(), server.URL, NewAccessKeyProvider("ak", "sk"), WithTokenCache(cache, key))
+		if err != nil {
+			t.Fatalf("failed to init client: %v", err)
+		}
+		return api
+	}
+
+	getScript := func(api *ClientWithResponses) {
+		t.Helper()
+
+		resp, err := api.GetScriptWithResponse(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("GetScriptWithResponse failed: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("expected HTTP 200 but received %d", resp.StatusCode())
+		}
+	}
+
+	t.Run("stores token after login", func(t *testing.T) {
+		getScript(newClient())
+
+		if n := logins.Load(); n != 1 {
+			t.Fatalf("expected 1 login, got %d", n)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("expected token cache to be written: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Fatalf("expected token cache permissions 0600, got %o", perm)
+		}
+	})
+
+	t.Run("reuses cached token", func(t *testing.T) {
+		getScript(newClient())
+
+		if n := logins.Load(); n != 1 {
+			t.Fatalf("expected cached token to be reused, got %d logins", n)
+		}
+	})
+
+	t.Run("logs in again when cached token is rejected", func(t *testing.T) {
+		cached, err := cache.Load(key)
+		if err != nil || cached == nil {
+			t.Fatalf("expected a cached token, got %v, %v", cached, err)
+		}
+		rejectedToken.Store(cached.Token)
+
+		getScript(newClient())
+
+		if n := logins.Load(); n != 2 {
+			t.Fatalf("expected 2 logins, got %d", n)
+		}
+
+		renewed, err := cache.Load(key)
+		if err != nil || renewed == nil {
+			t.Fatalf("expected a cached token, got %v, %v", renewed, err)
+		}
+		if renewed.Token == cached.Token {
+			t.Fatal("expected rejected token to be replaced in the cache")
+		}
+	})
+
+	t.Run("logs in again when cached token is expiring", func(t *testing.T) {
+		expiring := &CachedToken{Token: testJWT(t, 0, time.Now().Add(tokenRefreshLeeway/2))}
+		if err := cache.Store(key, expiring); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+
+		getScript(newClient())
+
+		if n := logins.Load(); n != 3 {
+			t.Fatalf("expected 3 logins, got %d", n)
+		}
+	})
+
+	t.Run("other identities don't share tokens", func(t *testing.T) {
+		for _, key := range []string{TokenCacheKey(server.URL, "", "other", "sk"), TokenCacheKey(server.URL, "", "ak", "other")} {
+			other, err := cache.Load(key)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if other != nil {
+				t.Fatalf("expected no token for another identity or secret, got %+v", other)
+			}
+		}
+	})
+
+	t.Run("clear removes all tokens", func(t *testing.T) {
+		if err := cache.Clear(); err != nil {
+			t.Fatalf("Clear failed: %v", err)
+		}
+
+		cached, err := cache.Load(key)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cached != nil {
+			t.Fatalf("expected no cached token after Clear, got %+v", cached)
+		}
+	})
+
+	t.Run("concurrent writers never leave a partial cache", func(t *testing.T) {
+		// Each cache stands in for a separate CLI process, which doesn't
+		// share the others' lock.
+		// Large tokens make each write long enough for readers to catch a
+		// partly written file.
+		token := strings.Repeat("t", 1<<16)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 4)
+		for i := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				c := NewFileTokenCache(path)
+				for range 50 {
+					if err := c.Store(strconv.Itoa(i), &CachedToken{Token: token}); err != nil {
+						errs <- err
+						return
+					}
+					if _, err := c.Load(strconv.Itoa(i)); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Fatalf("concurrent access failed: %v", err)
+		}
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected only the cache file to be left, got %v", entries)
+		}
+	})
+}
@@ -5,11 +5,8 @@ package main
 import (
 	"context"
 	"crypto/rand"
-	"encoding/base64"
 	"log"
-	"net/url"
 	"os"
-	"strconv"
 
 	"github.com/jansdhillon/landscape-go-api-client/client"
 )
@@ -42,69 +39,33 @@ func main() {
 	}
 
 	// Create a V2 script
-	createParams := client.LegacyActionParams("CreateScript")
-	rawCode := "#!/bin/bash\n \"hello\" > /home/ubuntu/hello.txt"
-	enc := base64.StdEncoding.EncodeToString([]byte(rawCode))
-	queryArgsEditorFn := client.EncodeQueryRequestEditor(url.Values{
-		"title":       []string{rand.Text()},
-		"code":        []string{enc},
-		"script_type": []string{"V2"},
+	scripts := landscapeAPIClient.Scripts()
+	created, err := scripts.Create(ctx, client.CreateScriptParams{
+		Title:      rand.Text(),
+		Code:       "#!/bin/bash\n \"hello\" > /home/ubuntu/hello.txt",
+		ScriptType: client.ScriptTypeV2,
 	})
-	createdScriptRes, err := landscapeAPIClient.InvokeLegacyActionWithResponse(ctx, createParams, queryArgsEditorFn)
 	if err != nil {
-		log.Fatalf("failed to invoke legacy action: %v", err)
+		log.Fatalf("failed to create script: %v", err)
 	}
 
-	log.Printf("raw create script response: %s", createdScriptRes.Body)
-	if createdScriptRes.JSON200 == nil {
-		if createdScriptRes.JSON404 != nil {
-			log.Fatalf("error getting script: %s", createdScriptRes.Status())
-		}
-	}
-
-	script, err := createdScriptRes.JSON200.AsScriptResult()
-	if err != nil {
-		log.Fatalf("failed to parse response as script: %v", err)
-	}
-
-	createdScript, err := script.AsV1Script()
-	if err != nil {
-		log.Fatalf("failed to parse response as V2 script: %v", err)
-	}
-
-	editParams := client.LegacyActionParams("EditScript")
-	raw := "#!/bin/bash\necho \"newcode\" > /home/ubuntu/myscript.txt"
-	enc = base64.StdEncoding.EncodeToString([]byte(raw))
-	queryArgsEditorFn = client.EncodeQueryRequestEditor(url.Values{
-		"script_id": []string{strconv.Itoa(createdScript.Id)},
-		"username":  []string{"jim"},
-		"code":      []string{enc},
+	code := "#!/bin/bash\necho \"newcode\" > /home/ubuntu/myscript.txt"
+	username := "jim"
+	edited, err := scripts.Edit(ctx, created.GetID(), client.EditScriptParams{
+		Code:     &code,
+		Username: &username,
 	})
-
-	res, err := landscapeAPIClient.InvokeLegacyActionWithResponse(ctx, editParams, queryArgsEditorFn)
 	if err != nil {
-		log.Fatalf("failed to invoke legacy action: %v", err)
+		log.Fatalf("failed to edit script: %v", err)
 	}
 
-	log.Printf("raw edit script response: %s", res.Body)
-	if res.JSON200 == nil {
-		log.Fatalf("failed to edit script: %s", res.Status())
-	}
-
-	script, err = res.JSON200.AsScriptResult()
-	if err != nil {
-		log.Fatalf("failed to parse response as script: %s", err)
-	}
-
-	editedScript, err := script.AsV2Script()
-	if err != nil {
-		log.Fatalf("failed to script as V2 script: %s", err)
+	editedScript, ok := edited.(*client.V2Script)
+	if !ok {
+		log.Fatalf("expected a V2 script, got %T", edited)
 	}
 
 	log.Printf("edited script title: %s", editedScript.Title)
-	if editedScript.Attachments != nil {
-		log.Printf("edited script attachments: %+v", *editedScript.Attachments)
-	}
+	log.Printf("edited script version: %d", editedScript.GetVersion())
 	if editedScript.Attachments != nil {
 		log.Printf("edited script attachments count: %d", len(*editedScript.Attachments))
 		for i, attachment := range *editedScript.Attachments {
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+var accountCmd = &cli.Command{
+	Name:  "account",
+	Usage: "List and switch between the Landscape accounts you can access.",
+	Commands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List the accounts available to the logged in user.",
+			Action: listAccountsAction,
+		},
+		{
+			Name:      "use",
+			Usage:     "Switch the active account without logging in again, saving it to the context in use. Without a context, pass -account instead.",
+			ArgsUsage: "[account-name]",
+			Action:    useAccountAction,
+		},
+	},
+}
+
+type accountList struct {
+	CurrentAccount string                `json:"current_account"`
+	Accounts       []client.LoginAccount `json:"accounts"`
+}
+
+func listAccountsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	accounts, current, err := api.Accounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, accountList{CurrentAccount: current, Accounts: accounts})
+}
+
+func useAccountAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	name := cmd.Args().First()
+	if name == "" {
+		return fmt.Errorf("account name must be provided as the first argument")
+	}
+
+	// The switch only lasts for this command, so it's pointless unless it
+	// can be saved for the next ones.
+	contextName, _ := ctx.Value(configContextKey).(string)
+	if contextName == "" {
+		return fmt.Errorf("no context to save the account to: pass -%s %s (or set LANDSCAPE_ACCOUNT) with each command, or create a context with config set-context", accountFlag, name)
+	}
+
+	if err := api.SwitchAccount(ctx, name); err != nil {
+		return err
+	}
+
+	if err := saveContextAccount(contextName, name); err != nil {
+		return err
+	}
+
+	accounts, current, err := api.Accounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, accountList{CurrentAccount: current, Accounts: accounts})
+}
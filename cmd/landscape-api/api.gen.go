@@ -0,0 +1,274 @@
+// Code generated by cligen. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/urfave/cli/v3"
+)
+
+// apiCommands has a command for every operation of the API client.
+var apiCommands = []*cli.Command{
+	archiveScriptAPICmd,
+	getScriptAPICmd,
+	getScriptAttachmentAPICmd,
+	invokeLegacyActionAPICmd,
+	loginWithAccessKeyAPICmd,
+	loginWithPasswordAPICmd,
+	redactScriptAPICmd,
+}
+
+// archiveScriptAPICmd calls ArchiveScript (POST /api/scripts/{script_id}:archive).
+var archiveScriptAPICmd = &cli.Command{
+	Name:      "archive-script",
+	Usage:     "Archive a V2 script.",
+	ArgsUsage: "[script-id]",
+	Action:    archiveScriptAPIAction,
+}
+
+func archiveScriptAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptId, err := intArg(cmd, 0, "script-id")
+	if err != nil {
+		return err
+	}
+
+	res, err := api.ArchiveScriptWithResponse(ctx, client.ScriptIdPathParam(scriptId))
+	if err != nil {
+		return fmt.Errorf("failed to call ArchiveScript: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, nil)
+}
+
+// getScriptAPICmd calls GetScript (GET /api/scripts/{script_id}).
+var getScriptAPICmd = &cli.Command{
+	Name:      "get-script",
+	Usage:     "Get a script.",
+	ArgsUsage: "[script-id]",
+	Action:    getScriptAPIAction,
+}
+
+func getScriptAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptId, err := intArg(cmd, 0, "script-id")
+	if err != nil {
+		return err
+	}
+
+	res, err := api.GetScriptWithResponse(ctx, client.ScriptIdPathParam(scriptId))
+	if err != nil {
+		return fmt.Errorf("failed to call GetScript: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, res.JSON200)
+}
+
+// getScriptAttachmentAPICmd calls GetScriptAttachment (GET /api/scripts/{script_id}/attachments/{attachment_id}).
+var getScriptAttachmentAPICmd = &cli.Command{
+	Name:      "get-script-attachment",
+	Usage:     "Get the contents of a script attachment.",
+	ArgsUsage: "[script-id] [attachment-id]",
+	Action:    getScriptAttachmentAPIAction,
+}
+
+func getScriptAttachmentAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptId, err := intArg(cmd, 0, "script-id")
+	if err != nil {
+		return err
+	}
+
+	attachmentId, err := intArg(cmd, 1, "attachment-id")
+	if err != nil {
+		return err
+	}
+
+	res, err := api.GetScriptAttachmentWithResponse(ctx, client.ScriptIdPathParam(scriptId), client.ScriptAttachmentIdPathParam(attachmentId))
+	if err != nil {
+		return fmt.Errorf("failed to call GetScriptAttachment: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, res.JSON200)
+}
+
+// invokeLegacyActionAPICmd calls InvokeLegacyAction (POST /api).
+var invokeLegacyActionAPICmd = &cli.Command{
+	Name:        "invoke-legacy-action",
+	Usage:       "Invoke a legacy API action.",
+	Description: "Calls an action of the legacy query API. The parameters of the action are sent as query parameters.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "version",
+			Usage: "The legacy API version. Landscape currently expects the fixed value `2011-08-01`.",
+			Value: "2011-08-01",
+		},
+		&cli.StringFlag{
+			Name:     "action",
+			Usage:    "The legacy API action name to invoke.",
+			Required: true,
+		},
+	},
+	Action: invokeLegacyActionAPIAction,
+}
+
+func invokeLegacyActionAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	params := &client.InvokeLegacyActionParams{}
+	params.Version = client.LegacyVersionParam(cmd.String("version"))
+	params.Action = client.LegacyActionParam(cmd.String("action"))
+
+	res, err := api.InvokeLegacyActionWithResponse(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to call InvokeLegacyAction: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, res.JSON200)
+}
+
+// loginWithAccessKeyAPICmd calls LoginWithAccessKey (POST /api/login/access-key).
+var loginWithAccessKeyAPICmd = &cli.Command{
+	Name:  "login-with-access-key",
+	Usage: "Log in with an access key and secret key.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "body",
+			Usage: "The request body as JSON. The flags for its fields are applied on top of it.",
+		},
+		&cli.StringFlag{
+			Name:  "access-key",
+			Usage: "Access key issued for API authentication. Required.",
+		},
+		&cli.StringFlag{
+			Name:  "secret-key",
+			Usage: "Secret key paired with the access key. Required.",
+		},
+	},
+	Action: loginWithAccessKeyAPIAction,
+}
+
+func loginWithAccessKeyAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	var body client.LoginWithAccessKeyJSONRequestBody
+	if err := decodeAPIBody(cmd, "body", &body); err != nil {
+		return err
+	}
+	if cmd.IsSet("access-key") {
+		body.AccessKey = cmd.String("access-key")
+	}
+	if cmd.IsSet("secret-key") {
+		body.SecretKey = cmd.String("secret-key")
+	}
+
+	res, err := api.LoginWithAccessKeyWithResponse(ctx, body)
+	if err != nil {
+		return fmt.Errorf("failed to call LoginWithAccessKey: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, res.JSON200)
+}
+
+// loginWithPasswordAPICmd calls LoginWithPassword (POST /api/login).
+var loginWithPasswordAPICmd = &cli.Command{
+	Name:  "login-with-password",
+	Usage: "Log in with an email and password.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "body",
+			Usage: "The request body as JSON. The flags for its fields are applied on top of it.",
+		},
+		&cli.StringFlag{
+			Name:  "account",
+			Usage: "The account to login into (optional).",
+		},
+		&cli.StringFlag{
+			Name:  "email",
+			Usage: "Email address used to authenticate with Landscape Server. Required.",
+		},
+		&cli.StringFlag{
+			Name:  "password",
+			Usage: "Password associated with the provided email. Required.",
+		},
+	},
+	Action: loginWithPasswordAPIAction,
+}
+
+func loginWithPasswordAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	var body client.LoginWithPasswordJSONRequestBody
+	if err := decodeAPIBody(cmd, "body", &body); err != nil {
+		return err
+	}
+	if cmd.IsSet("account") {
+		v := cmd.String("account")
+		body.Account = &v
+	}
+	if cmd.IsSet("email") {
+		body.Email = openapi_types.Email(cmd.String("email"))
+	}
+	if cmd.IsSet("password") {
+		body.Password = cmd.String("password")
+	}
+
+	res, err := api.LoginWithPasswordWithResponse(ctx, body)
+	if err != nil {
+		return fmt.Errorf("failed to call LoginWithPassword: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, res.JSON200)
+}
+
+// redactScriptAPICmd calls RedactScript (POST /api/scripts/{script_id}:redact).
+var redactScriptAPICmd = &cli.Command{
+	Name:      "redact-script",
+	Usage:     "Redact a V2 script.",
+	ArgsUsage: "[script-id]",
+	Action:    redactScriptAPIAction,
+}
+
+func redactScriptAPIAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptId, err := intArg(cmd, 0, "script-id")
+	if err != nil {
+		return err
+	}
+
+	res, err := api.RedactScriptWithResponse(ctx, client.ScriptIdPathParam(scriptId))
+	if err != nil {
+		return fmt.Errorf("failed to call RedactScript: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, nil)
+}
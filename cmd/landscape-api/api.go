@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+)
+
+// apiCmd groups the commands generated from the OpenAPI spec by cligen,
+// which are in api.gen.go.
+var apiCmd = &cli.Command{
+	Name:  "api",
+	Usage: "Call any operation of the REST API.",
+	Description: `There is a command for every operation in the OpenAPI spec, named after
+its operation ID. Path parameters are given as arguments, and query
+parameters and the fields of JSON request bodies as flags. For example:
+
+   landscape-api api get-script 21433
+   landscape-api api invoke-legacy-action -action GetComputers`,
+	Commands: apiCommands,
+}
+
+// stringArg returns the command's i-th argument, which is called name.
+func stringArg(cmd *cli.Command, i int, name string) (string, error) {
+	if cmd.Args().Len() <= i {
+		return "", fmt.Errorf("%s must be provided as argument %d", name, i+1)
+	}
+	return cmd.Args().Get(i), nil
+}
+
+func intArg(cmd *cli.Command, i int, name string) (int, error) {
+	s, err := stringArg(cmd, i, name)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't convert %s to int: %s", name, err)
+	}
+	return n, nil
+}
+
+func floatArg(cmd *cli.Command, i int, name string) (float64, error) {
+	s, err := stringArg(cmd, i, name)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't convert %s to float: %s", name, err)
+	}
+	return f, nil
+}
+
+func boolArg(cmd *cli.Command, i int, name string) (bool, error) {
+	s, err := stringArg(cmd, i, name)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("couldn't convert %s to bool: %s", name, err)
+	}
+	return b, nil
+}
+
+// decodeAPIBody decodes the JSON given with the flag called name into body,
+// if it was given.
+func decodeAPIBody(cmd *cli.Command, name string, body any) error {
+	raw := cmd.String(name)
+	if raw == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), body); err != nil {
+		return fmt.Errorf("invalid -%s: %w", name, err)
+	}
+	return nil
+}
+
+// openAPIBody opens the file given with the flag called name, or stdin if
+// it's -.
+func openAPIBody(cmd *cli.Command, name string) (io.ReadCloser, error) {
+	path := cmd.String(name)
+	switch path {
+	case "":
+		return nil, fmt.Errorf("-%s must be provided", name)
+	case "-":
+		return io.NopCloser(cmd.Root().Reader), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return f, nil
+}
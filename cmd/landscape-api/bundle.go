@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/jansdhillon/landscape-go-api-client/internal/bundle"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	skipFailedAttachmentsFlag = "skip-failed-attachments"
+	accessGroupMapFlag        = "access-group-map"
+	onConflictFlag            = "on-conflict"
+	stateFlag                 = "state"
+)
+
+var exportScriptsCmd = &cli.Command{
+	Name:  "export",
+	Usage: "Write every script, with its code, settings and attachments, to a tar.gz archive that script import can read.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     fileFlag,
+			Aliases:  []string{"f"},
+			Usage:    "The archive to write, such as scripts.tar.gz.",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  forceFlag,
+			Usage: "Overwrite the archive if it already exists.",
+		},
+		&cli.BoolFlag{
+			Name:  skipFailedAttachmentsFlag,
+			Usage: "Leave out attachments that can't be downloaded, listing them in the manifest, rather than failing the export.",
+		},
+	},
+	Action: exportScriptsAction,
+}
+
+var importScriptsCmd = &cli.Command{
+	Name:  "import",
+	Usage: "Create the scripts of an archive written by script export.",
+	Description: `Scripts are created with their attachments, and those that were archived
+are archived again. Progress is saved to a state file, so if the import
+fails part way, running the same command again resumes it without
+creating scripts twice.
+
+The access group map is a YAML file mapping the access groups of the
+exported scripts to those of this server:
+
+   global: global
+   saas-servers: servers`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     fileFlag,
+			Aliases:  []string{"f"},
+			Usage:    "The archive to import.",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  accessGroupMapFlag,
+			Usage: "A YAML file mapping exported access groups to those of this server. Access groups that aren't mapped are kept.",
+		},
+		&cli.StringFlag{
+			Name:  onConflictFlag,
+			Usage: "What to do with a script whose title is already used: skip it, or rename it with a number added.",
+			Value: string(bundle.ConflictSkip),
+		},
+		&cli.StringFlag{
+			Name:  stateFlag,
+			Usage: "The file recording the progress of the import. Defaults to the archive's path with .state.json added.",
+		},
+	},
+	Action: importScriptsAction,
+}
+
+func exportScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	path := cmd.String(fileFlag)
+	if _, err := os.Stat(path); err == nil && !cmd.Bool(forceFlag) {
+		return fmt.Errorf("%s already exists, pass -%s to overwrite it", path, forceFlag)
+	}
+
+	// The archive is written to a temporary file first, so a failed
+	// export doesn't leave a partial one behind.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".landscape-export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	opts := bundle.ExportOptions{SkipFailedAttachments: cmd.Bool(skipFailedAttachmentsFlag)}
+	if raw, ok := api.ClientInterface.(*client.Client); ok {
+		opts.Server = raw.Server
+	}
+
+	manifest, err := bundle.Export(ctx, api.Scripts(), tmp, opts)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if errors.Is(err, bundle.ErrAttachmentExport) {
+		return fmt.Errorf("%w; pass -%s to leave out attachments that can't be downloaded", err, skipFailedAttachmentsFlag)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+
+	for _, s := range manifest.Scripts {
+		for _, filename := range s.MissingAttachments {
+			fmt.Fprintf(cmd.Root().ErrWriter, "script %d: attachment %s couldn't be downloaded, so it was left out\n", s.ID, filename)
+		}
+	}
+	fmt.Fprintf(cmd.Root().ErrWriter, "exported %d scripts to %s\n", len(manifest.Scripts), path)
+
+	return WriteValueToRoot(ctx, cmd, manifest.Scripts)
+}
+
+func importScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	path := cmd.String(fileFlag)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Read(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	opts := bundle.ImportOptions{OnConflict: bundle.Conflict(cmd.String(onConflictFlag))}
+
+	if mapPath := cmd.String(accessGroupMapFlag); mapPath != "" {
+		data, err := os.ReadFile(mapPath)
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(data, &opts.AccessGroups); err != nil {
+			return fmt.Errorf("invalid access group map %s: %w", mapPath, err)
+		}
+	}
+
+	statePath := cmd.String(stateFlag)
+	if statePath == "" {
+		statePath = path + ".state.json"
+	}
+	if opts.State, err = bundle.LoadState(statePath); err != nil {
+		return err
+	}
+
+	results, err := bundle.Import(ctx, api.Scripts(), b, opts)
+	if results == nil {
+		// Nothing was imported, so there is nothing to resume.
+		return err
+	}
+	if writeErr := WriteValueToRoot(ctx, cmd, results); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w; run the same command again to resume the import", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+const apiVersionFlag = "api-version"
+
+var callCmd = &cli.Command{
+	Name:  "call",
+	Usage: "Call any legacy API action.",
+	Description: `Parameters are given as arguments after the action:
+
+   key=value     a string
+   key=[a,b,c]   a list of strings, sent as key.1=a&key.2=b&key.3=c
+   key:=json     a JSON value, such as a number, boolean, list or object
+   key@=path     the base64 encoded contents of a file
+
+Repeating a key also makes a list. A string that starts with [ and ends
+with ] is given as a JSON string, such as key:='"[a,b]"'. For example:
+
+   landscape-api call GetComputers query=tag:web limit:=10
+   landscape-api call ExecuteScript script_id:=21433 query=tag:web username=root
+   landscape-api call EditScript script_id:=21433 title:='"[nightly] backup"'`,
+	ArgsUsage: "[action] [key=value...]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  apiVersionFlag,
+			Usage: "The version of the legacy API to call.",
+			Value: client.LegacyActionParams("").Version,
+		},
+	},
+	Action: callAction,
+}
+
+func callAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	action := cmd.Args().First()
+	if action == "" {
+		return fmt.Errorf("action must be provided as the first argument")
+	}
+
+	params, err := parseCallParams(cmd.Args().Tail())
+	if err != nil {
+		return err
+	}
+
+	values, err := client.EncodeLegacyParams(params)
+	if err != nil {
+		return err
+	}
+
+	res, err := api.InvokeLegacyAction(ctx, &client.InvokeLegacyActionParams{
+		Action:  action,
+		Version: cmd.String(apiVersionFlag),
+	}, client.EncodeQueryRequestEditor(values))
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", action, err)
+	}
+
+	return WriteResponseToRoot(ctx, cmd, res)
+}
+
+// parseCallParams parses the key=value arguments of the call command.
+func parseCallParams(args []string) (map[string]any, error) {
+	params := map[string]any{}
+
+	for _, arg := range args {
+		key, value, err := parseCallParam(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, ok := params[key]
+		switch {
+		case !ok:
+			params[key] = value
+		case isList(existing):
+			params[key] = append(existing.([]any), value)
+		default:
+			params[key] = []any{existing, value}
+		}
+	}
+
+	return params, nil
+}
+
+func parseCallParam(arg string) (string, any, error) {
+	i := strings.Index(arg, "=")
+	if i <= 0 {
+		return "", nil, fmt.Errorf("invalid parameter %q: must be key=value, key:=json or key@=path", arg)
+	}
+
+	key, value := arg[:i], arg[i+1:]
+
+	switch {
+	case strings.HasSuffix(key, ":"):
+		key = strings.TrimSuffix(key, ":")
+
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.UseNumber()
+
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return "", nil, fmt.Errorf("invalid JSON for parameter %s: %w", key, err)
+		}
+		return key, v, nil
+	case strings.HasSuffix(key, "@"):
+		key = strings.TrimSuffix(key, "@")
+
+		contents, err := os.ReadFile(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read parameter %s: %w", key, err)
+		}
+		return key, contents, nil
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		list := []any{}
+		if inner := strings.TrimSpace(value[1 : len(value)-1]); inner != "" {
+			for _, item := range strings.Split(inner, ",") {
+				list = append(list, strings.TrimSpace(item))
+			}
+		}
+		return key, list, nil
+	default:
+		return key, value, nil
+	}
+}
+
+func isList(v any) bool {
+	_, ok := v.([]any)
+	return ok
+}
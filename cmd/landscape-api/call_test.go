@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCallParams(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(file, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name: "string",
+			args: []string{"query=tag:web"},
+			want: map[string]any{"query": "tag:web"},
+		},
+		{
+			name: "string with =",
+			args: []string{"query=title=backup"},
+			want: map[string]any{"query": "title=backup"},
+		},
+		{
+			name: "empty string",
+			args: []string{"query="},
+			want: map[string]any{"query": ""},
+		},
+		{
+			name: "list",
+			args: []string{"tags=[web, db]"},
+			want: map[string]any{"tags": []any{"web", "db"}},
+		},
+		{
+			name: "empty list",
+			args: []string{"tags=[]"},
+			want: map[string]any{"tags": []any{}},
+		},
+		{
+			name: "JSON number and boolean",
+			args: []string{"limit:=10", "with_network:=true"},
+			want: map[string]any{"limit": json.Number("10"), "with_network": true},
+		},
+		{
+			name: "JSON list and object",
+			args: []string{"ids:=[1,2]", "filter:={\"tag\":\"web\"}"},
+			want: map[string]any{"ids": []any{json.Number("1"), json.Number("2")}, "filter": map[string]any{"tag": "web"}},
+		},
+		{
+			name: "JSON string that looks like a list",
+			args: []string{`title:="[nightly] backup"`},
+			want: map[string]any{"title": "[nightly] backup"},
+		},
+		{
+			name: "file",
+			args: []string{"code@=" + file},
+			want: map[string]any{"code": []byte("#!/bin/sh\n")},
+		},
+		{
+			name: "repeated key",
+			args: []string{"tags=web", "tags=db", "tags=[cache]"},
+			want: map[string]any{"tags": []any{"web", "db", []any{"cache"}}},
+		},
+		{
+			name:    "no =",
+			args:    []string{"query"},
+			wantErr: true,
+		},
+		{
+			name:    "no key",
+			args:    []string{"=web"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			args:    []string{"limit:=ten"},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			args:    []string{"code@=" + filepath.Join(t.TempDir(), "missing.sh")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCallParams(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCallParams failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
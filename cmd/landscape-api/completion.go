@@ -0,0 +1,327 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	// completionFlag is appended to the command line by the completion
+	// scripts to ask for suggestions.
+	completionFlag = "--generate-shell-completion"
+
+	// completionShellEnvVar is set by the zsh and fish completion scripts,
+	// which read suggestions with descriptions.
+	completionShellEnvVar = "LANDSCAPE_COMPLETION_SHELL"
+
+	// zshCompletionCommand is how the zsh completion script generated by
+	// urfave/cli runs the command line being completed.
+	zshCompletionCommand = "${words[@]:0:#words[@]-1}"
+
+	// completionTimeout bounds the requests made while completing, so a
+	// slow server doesn't hang the shell.
+	completionTimeout = 5 * time.Second
+
+	// completionLimit is the largest number of scripts suggested.
+	completionLimit = 200
+)
+
+// fishDynamicCompletion is added to the fish completion script generated by
+// urfave/cli, which only completes commands and flags, so that script and
+// attachment IDs are suggested as well.
+const fishDynamicCompletion = `
+function __fish_%[1]s_dynamic_complete
+    set -l args (commandline -opc)
+    env %[2]s=fish $args %[3]s 2>/dev/null
+end
+
+complete -c %[1]s -f -n '__fish_seen_subcommand_from script script-profile' -a '(__fish_%[1]s_dynamic_complete)'
+`
+
+// configureCompletionCommand makes the completion command that urfave/cli
+// adds visible and extends its zsh and fish scripts, so that they tell the
+// command which format to suggest values in.
+func configureCompletionCommand(c *cli.Command) {
+	c.Hidden = false
+	c.Usage = "Output a shell completion script for bash, zsh, fish or pwsh."
+	c.ArgsUsage = "[bash|zsh|fish|pwsh]"
+
+	printScript := c.Action
+	c.Action = func(ctx context.Context, cmd *cli.Command) error {
+		writer := cmd.Writer
+		var script strings.Builder
+		cmd.Writer = &script
+		err := printScript(ctx, cmd)
+		cmd.Writer = writer
+		if err != nil {
+			return err
+		}
+
+		out := cmd.Root().Writer
+
+		switch cmd.Args().First() {
+		case "zsh":
+			_, err = io.WriteString(out, strings.ReplaceAll(script.String(), zshCompletionCommand, completionShellEnvVar+"=zsh "+zshCompletionCommand))
+		case "fish":
+			if _, err = io.WriteString(out, script.String()); err == nil {
+				_, err = fmt.Fprintf(out, fishDynamicCompletion, cmd.Root().Name, completionShellEnvVar, completionFlag)
+			}
+		default:
+			_, err = io.WriteString(out, script.String())
+		}
+		return err
+	}
+}
+
+// suggestion is a completion candidate and the hint shown next to it by
+// shells that support them.
+type suggestion struct {
+	value string
+	hint  string
+}
+
+// writeSuggestions writes suggestions in the format the shell's completion
+// script expects: value:hint for zsh, value<tab>hint for fish, and just the
+// value for bash.
+func writeSuggestions(w io.Writer, suggestions []suggestion) {
+	shell := os.Getenv(completionShellEnvVar)
+
+	for _, s := range suggestions {
+		hint := strings.Join(strings.Fields(s.hint), " ")
+
+		switch {
+		case shell == "zsh" && hint != "":
+			fmt.Fprintf(w, "%s:%s\n", strings.ReplaceAll(s.value, ":", `\:`), hint)
+		case shell == "fish" && hint != "":
+			fmt.Fprintf(w, "%s\t%s\n", s.value, hint)
+		default:
+			fmt.Fprintln(w, s.value)
+		}
+	}
+}
+
+// previousWord returns the last word on the command line before the one
+// being completed.
+func previousWord() string {
+	args := os.Args
+	if len(args) > 0 && args[len(args)-1] == completionFlag {
+		args = args[:len(args)-1]
+	}
+	if len(args) < 2 {
+		return ""
+	}
+	return args[len(args)-1]
+}
+
+// completionClient connects to Landscape the same way the root command
+// does, so a cached token is reused. It returns nil if it can't, since
+// completion has nowhere to report errors.
+func completionClient(ctx context.Context, cmd *cli.Command) *client.ClientWithResponses {
+	root := cmd.Root()
+
+	conn, err := resolveConnection(root)
+	if err != nil {
+		return nil
+	}
+
+	ctx, err = connect(ctx, root, conn)
+	if err != nil {
+		return nil
+	}
+
+	api, _ := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	return api
+}
+
+// scriptSuggestions returns the IDs of the scripts on the server, with
+// their titles as hints, leaving out the IDs in exclude.
+func scriptSuggestions(ctx context.Context, cmd *cli.Command, exclude []string) []suggestion {
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	api := completionClient(ctx, cmd)
+	if api == nil {
+		return nil
+	}
+
+	var suggestions []suggestion
+	for script, err := range api.Scripts().All(ctx, client.ListScriptsParams{Limit: completionLimit}) {
+		if err != nil || len(suggestions) == completionLimit {
+			break
+		}
+
+		id := strconv.Itoa(script.GetID())
+		if slices.Contains(exclude, id) {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{value: id, hint: script.GetTitle()})
+	}
+
+	return suggestions
+}
+
+// scriptProfileSuggestions returns the IDs of the script profiles on the
+// server, with their titles as hints, leaving out the IDs in exclude.
+func scriptProfileSuggestions(ctx context.Context, cmd *cli.Command, exclude []string) []suggestion {
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	api := completionClient(ctx, cmd)
+	if api == nil {
+		return nil
+	}
+
+	profiles, err := api.ScriptProfiles().List(ctx)
+	if err != nil {
+		return nil
+	}
+
+	var suggestions []suggestion
+	for _, p := range profiles {
+		id := strconv.Itoa(p.Id)
+		if p.Archived || slices.Contains(exclude, id) {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{value: id, hint: p.Title})
+	}
+
+	return suggestions
+}
+
+// attachmentSuggestions returns the attachments of the script with the
+// given ID, as their IDs or their filenames.
+func attachmentSuggestions(ctx context.Context, cmd *cli.Command, scriptID int, byFilename bool, exclude []string) []suggestion {
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	api := completionClient(ctx, cmd)
+	if api == nil {
+		return nil
+	}
+
+	attachments, err := api.Scripts().ListAttachments(ctx, scriptID)
+	if err != nil {
+		return nil
+	}
+
+	var suggestions []suggestion
+	for _, a := range attachments {
+		s := suggestion{value: strconv.Itoa(a.Id), hint: a.Filename}
+		if byFilename {
+			s = suggestion{value: a.Filename}
+		} else if a.Id == 0 {
+			// Attachments without an ID can't be given to
+			// -script-attachment-id.
+			continue
+		}
+		if !slices.Contains(exclude, s.value) {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	return suggestions
+}
+
+// completeScriptID suggests a script ID for commands taking one as their
+// only argument.
+func completeScriptID(ctx context.Context, cmd *cli.Command) {
+	if strings.HasPrefix(previousWord(), "-") || cmd.Args().Present() {
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+		return
+	}
+
+	writeSuggestions(cmd.Root().Writer, scriptSuggestions(ctx, cmd, nil))
+}
+
+// completeScriptIDs suggests the script IDs that haven't been given yet,
+// for commands taking any number of them.
+func completeScriptIDs(ctx context.Context, cmd *cli.Command) {
+	if strings.HasPrefix(previousWord(), "-") {
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+		return
+	}
+
+	writeSuggestions(cmd.Root().Writer, scriptSuggestions(ctx, cmd, cmd.Args().Slice()))
+}
+
+// completeScriptProfileID suggests a script profile ID for commands taking
+// one as their only argument.
+func completeScriptProfileID(ctx context.Context, cmd *cli.Command) {
+	if strings.HasPrefix(previousWord(), "-") || cmd.Args().Present() {
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+		return
+	}
+
+	writeSuggestions(cmd.Root().Writer, scriptProfileSuggestions(ctx, cmd, nil))
+}
+
+// completeScriptProfileIDs suggests the script profile IDs that haven't
+// been given yet, for commands taking any number of them.
+func completeScriptProfileIDs(ctx context.Context, cmd *cli.Command) {
+	if strings.HasPrefix(previousWord(), "-") {
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+		return
+	}
+
+	writeSuggestions(cmd.Root().Writer, scriptProfileSuggestions(ctx, cmd, cmd.Args().Slice()))
+}
+
+// completeScriptProfileFlags suggests the values of the -script-id and
+// -trigger flags of script-profile create.
+func completeScriptProfileFlags(ctx context.Context, cmd *cli.Command) {
+	switch strings.TrimLeft(previousWord(), "-") {
+	case scriptIDFlag, "s":
+		writeSuggestions(cmd.Root().Writer, scriptSuggestions(ctx, cmd, nil))
+	case triggerFlag:
+		writeSuggestions(cmd.Root().Writer, []suggestion{{value: "event"}, {value: "recurring"}, {value: "one-time"}})
+	default:
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+	}
+}
+
+// completeAttachmentFilenames suggests a script ID, and then the filenames
+// of that script's attachments that haven't been given yet.
+func completeAttachmentFilenames(ctx context.Context, cmd *cli.Command) {
+	if strings.HasPrefix(previousWord(), "-") {
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+		return
+	}
+
+	if !cmd.Args().Present() {
+		writeSuggestions(cmd.Root().Writer, scriptSuggestions(ctx, cmd, nil))
+		return
+	}
+
+	scriptID, err := strconv.Atoi(cmd.Args().First())
+	if err != nil {
+		return
+	}
+	writeSuggestions(cmd.Root().Writer, attachmentSuggestions(ctx, cmd, scriptID, true, cmd.Args().Tail()))
+}
+
+// completeAttachmentFlags suggests the values of the -script-id and
+// -script-attachment-id flags, the latter from the attachments of the
+// script given with -script-id.
+func completeAttachmentFlags(ctx context.Context, cmd *cli.Command) {
+	switch strings.TrimLeft(previousWord(), "-") {
+	case scriptIDFlag, "s":
+		writeSuggestions(cmd.Root().Writer, scriptSuggestions(ctx, cmd, nil))
+	case scriptAttachmentIDFlag, "i":
+		if scriptID := int(cmd.Int64(scriptIDFlag)); scriptID != 0 {
+			writeSuggestions(cmd.Root().Writer, attachmentSuggestions(ctx, cmd, scriptID, false, nil))
+		}
+	default:
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+	}
+}
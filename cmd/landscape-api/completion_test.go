@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSuggestions(t *testing.T) {
+	suggestions := []suggestion{
+		{value: "21433", hint: "nightly  backup\n"},
+		{value: "a:b.conf", hint: "config"},
+		{value: "exclude.txt"},
+	}
+
+	tests := []struct {
+		name  string
+		shell string
+		want  string
+	}{
+		{
+			name: "bash",
+			want: "21433\na:b.conf\nexclude.txt\n",
+		},
+		{
+			name:  "zsh",
+			shell: "zsh",
+			want:  "21433:nightly backup\na\\:b.conf:config\nexclude.txt\n",
+		},
+		{
+			name:  "fish",
+			shell: "fish",
+			want:  "21433\tnightly backup\na:b.conf\tconfig\nexclude.txt\n",
+		},
+		{
+			name:  "unknown shell",
+			shell: "pwsh",
+			want:  "21433\na:b.conf\nexclude.txt\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(completionShellEnvVar, tt.shell)
+			// The user's login shell doesn't decide the format, only
+			// the completion script does.
+			t.Setenv("SHELL", "/bin/zsh")
+
+			var out bytes.Buffer
+			writeSuggestions(&out, suggestions)
+			if got := out.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	isolateConfig(t)
+
+	zsh, err := runCLI(t, "completion", "zsh")
+	if err != nil {
+		t.Fatalf("completion zsh failed: %v", err)
+	}
+	if n := strings.Count(zsh, zshCompletionCommand); n == 0 || n != strings.Count(zsh, completionShellEnvVar+"=zsh "+zshCompletionCommand) {
+		t.Errorf("expected every completion in the zsh script to set %s, got\n%s", completionShellEnvVar, zsh)
+	}
+
+	fish, err := runCLI(t, "completion", "fish")
+	if err != nil {
+		t.Fatalf("completion fish failed: %v", err)
+	}
+	if !strings.Contains(fish, "env "+completionShellEnvVar+"=fish $args "+completionFlag) {
+		t.Errorf("expected the fish script to set %s, got\n%s", completionShellEnvVar, fish)
+	}
+
+	bash, err := runCLI(t, "completion", "bash")
+	if err != nil {
+		t.Fatalf("completion bash failed: %v", err)
+	}
+	if strings.Contains(bash, completionShellEnvVar) {
+		t.Errorf("expected the bash script not to set %s, got\n%s", completionShellEnvVar, bash)
+	}
+}
+
+func TestCompletionExcludesGivenIDs(t *testing.T) {
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"count": 3, "results": []map[string]any{
+			{"id": 1, "title": "backup", "status": "ACTIVE", "version_number": 1},
+			{"id": 2, "title": "restore", "status": "ACTIVE", "version_number": 1},
+			{"id": 3, "title": "legacy", "status": "V1", "attachments": []string{"a.txt", "b.conf"}},
+		}})
+	})
+	handler.HandleFunc("/api/scripts/1", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"id": 1, "title": "backup", "status": "ACTIVE", "version_number": 1, "attachments": []map[string]any{{"id": 7, "filename": "c.txt"}}})
+	})
+	handler.HandleFunc("/api/scripts/3", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"id": 3, "title": "legacy", "status": "V1", "attachments": []string{"a.txt", "b.conf"}})
+	})
+	handler.HandleFunc("/api/scripts/3/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"id": 8, "filename": "a.txt"}, {"id": 9, "filename": "b.conf"}})
+	})
+	handler.HandleFunc("/api/script-profiles", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"count": 3, "results": []map[string]any{
+			{"id": 5, "title": "nightly", "archived": false},
+			{"id": 6, "title": "weekly", "archived": false},
+			{"id": 7, "title": "old", "archived": true},
+		}})
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "script IDs",
+			args: []string{"script", "archive"},
+			want: "1\tbackup\n2\trestore\n3\tlegacy\n",
+		},
+		{
+			name: "given script IDs are left out",
+			args: []string{"script", "archive", "1", "3"},
+			want: "2\trestore\n",
+		},
+		{
+			name: "script profile IDs leave out archived and given ones",
+			args: []string{"script-profile", "archive", "6"},
+			want: "5\tnightly\n",
+		},
+		{
+			name: "given attachment filenames are left out",
+			args: []string{"script", "attachment", "remove", "3", "a.txt"},
+			want: "b.conf\n",
+		},
+		{
+			name: "attachment IDs of a V1 script",
+			args: []string{"script", "attachment", "get", "-script-id", "3", "-script-attachment-id"},
+			want: "8\ta.txt\n9\tb.conf\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfig(t)
+			t.Setenv("LANDSCAPE_BASE_URL", server.URL)
+			t.Setenv("LANDSCAPE_TOKEN", "token")
+			t.Setenv("LANDSCAPE_TOKEN_CACHE", filepath.Join(t.TempDir(), "tokens.json"))
+			t.Setenv(completionShellEnvVar, "fish")
+
+			// previousWord reads the command line from os.Args.
+			args := append(append([]string{"landscape-api"}, tt.args...), completionFlag)
+			oldArgs := os.Args
+			os.Args = args
+			defer func() { os.Args = oldArgs }()
+
+			got, err := runCLI(t, args[1:]...)
+			if err != nil {
+				t.Fatalf("completion failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	contextFlag    = "context"
+	authMethodFlag = "auth-method"
+	outputFlag     = "output"
+	useFlag        = "use"
+)
+
+const (
+	authMethodAccessKey = "access-key"
+	authMethodPassword  = "password"
+	authMethodToken     = "token"
+)
+
+const configContextKey ctxKey = "landscape-config-context"
+
+// cliConfig is the CLI's config file, holding named connection contexts.
+type cliConfig struct {
+	CurrentContext string                 `yaml:"current-context,omitempty"`
+	Contexts       map[string]*cliContext `yaml:"contexts,omitempty"`
+}
+
+// cliContext holds the settings for connecting to one Landscape server.
+type cliContext struct {
+	BaseURL    string `yaml:"base-url,omitempty"`
+	AuthMethod string `yaml:"auth-method,omitempty"`
+	AccessKey  string `yaml:"access-key,omitempty"`
+	SecretKey  string `yaml:"secret-key,omitempty"`
+	Email      string `yaml:"email,omitempty"`
+	Password   string `yaml:"password,omitempty"`
+	Account    string `yaml:"account,omitempty"`
+	CACert     string `yaml:"ca-cert,omitempty"`
+	Output     string `yaml:"output,omitempty"`
+}
+
+// configPath returns the path of the config file, which is either set by
+// LANDSCAPE_CONFIG or kept in the user's config directory.
+func configPath() (string, error) {
+	if path := os.Getenv("LANDSCAPE_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("couldn't find the config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "landscape-api", "config.yaml"), nil
+}
+
+// loadConfig reads the config file, returning an empty config if it
+// doesn't exist yet.
+func loadConfig() (*cliConfig, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &cliConfig{Contexts: map[string]*cliContext{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Contexts == nil {
+		cfg.Contexts = map[string]*cliContext{}
+	}
+
+	return cfg, nil
+}
+
+// save writes the config file. It may hold credentials, so only the user
+// can read it.
+func (cfg *cliConfig) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return os.Chmod(path, 0o600)
+}
+
+// context returns the context with the given name, or the current context
+// if name is empty. It returns nil if no context is selected.
+func (cfg *cliConfig) context(name string) (*cliContext, string, error) {
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+	if name == "" {
+		return nil, "", nil
+	}
+
+	profile, ok := cfg.Contexts[name]
+	if !ok {
+		return nil, "", fmt.Errorf("context %q not found in config", name)
+	}
+
+	return profile, name, nil
+}
+
+// connection holds the settings used to connect to Landscape, resolved
+// from flags and environment variables, falling back to the selected
+// context.
+type connection struct {
+	contextName string
+
+	baseURL    string
+	authMethod string
+	accessKey  string
+	secretKey  string
+	email      string
+	password   string
+	account    string
+	caCert     string
+	output     string
+}
+
+// resolveConnection merges the root command's flags with the context
+// selected by --context or the config's current context.
+func resolveConnection(c *cli.Command) (*connection, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	profile, name, err := cfg.context(c.String(contextFlag))
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &cliContext{}
+	}
+
+	value := func(flag, fallback string) string {
+		if c.IsSet(flag) {
+			return c.String(flag)
+		}
+		return fallback
+	}
+
+	return &connection{
+		contextName: name,
+		baseURL:     value(baseURLFlag, profile.BaseURL),
+		authMethod:  profile.AuthMethod,
+		accessKey:   value(accessKeyFlag, profile.AccessKey),
+		secretKey:   value(secretKeyFlag, profile.SecretKey),
+		email:       value(emailFlag, profile.Email),
+		password:    value(passwordFlag, profile.Password),
+		account:     value(accountFlag, profile.Account),
+		caCert:      value(caCertFlag, profile.CACert),
+		output:      profile.Output,
+	}, nil
+}
+
+var configCmd = &cli.Command{
+	Name:  "config",
+	Usage: "Manage named contexts for connecting to Landscape servers.",
+	Commands: []*cli.Command{
+		{
+			Name:      "set-context",
+			Usage:     "Create or update a context. Only the given flags are changed.",
+			ArgsUsage: "[context-name]",
+			Description: `The config file can only be read by you, but secret keys and passwords are
+stored in it in plain text. To keep them out of it, leave them out of the
+context and set LANDSCAPE_SECRET_KEY or LANDSCAPE_PASSWORD when running
+commands, or use the token auth method.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  baseURLFlag,
+					Usage: "The base URL of Landscape.",
+				},
+				&cli.StringFlag{
+					Name:  authMethodFlag,
+					Usage: "How to log in: access-key, password or token (read from LANDSCAPE_TOKEN).",
+				},
+				&cli.StringFlag{
+					Name:  accessKeyFlag,
+					Usage: "An access key for the Landscape API.",
+				},
+				&cli.StringFlag{
+					Name:  secretKeyFlag,
+					Usage: "A secret key for the Landscape API. It is stored in plain text in the config file.",
+				},
+				&cli.StringFlag{
+					Name:  emailFlag,
+					Usage: "An email to access the Landscape API.",
+				},
+				&cli.StringFlag{
+					Name:  passwordFlag,
+					Usage: "A password to access the Landscape API. It is stored in plain text in the config file.",
+				},
+				&cli.StringFlag{
+					Name:  accountFlag,
+					Usage: "The account to use.",
+				},
+				&cli.StringFlag{
+					Name:  caCertFlag,
+					Usage: "A PEM file of CA certificates to trust.",
+				},
+				&cli.StringFlag{
+					Name:  outputFlag,
+					Usage: "The default output format, such as table or yaml.",
+				},
+				&cli.BoolFlag{
+					Name:  useFlag,
+					Usage: "Also make this the current context.",
+				},
+			},
+			Action: setContextAction,
+		},
+		{
+			Name:      "use-context",
+			Usage:     "Set the current context.",
+			ArgsUsage: "[context-name]",
+			Action:    useContextAction,
+		},
+		{
+			Name:   "list",
+			Usage:  "List the configured contexts.",
+			Action: listContextsAction,
+		},
+	},
+}
+
+func setContextAction(ctx context.Context, cmd *cli.Command) error {
+	name := cmd.Args().First()
+	if name == "" {
+		return fmt.Errorf("context name must be provided as the first argument")
+	}
+
+	authMethod := cmd.String(authMethodFlag)
+	if authMethod != "" && authMethod != authMethodAccessKey && authMethod != authMethodPassword && authMethod != authMethodToken {
+		return fmt.Errorf("auth method must be %q, %q or %q", authMethodAccessKey, authMethodPassword, authMethodToken)
+	}
+
+	if cmd.IsSet(outputFlag) {
+		if _, err := parseOutput(cmd.String(outputFlag)); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile, ok := cfg.Contexts[name]
+	if !ok {
+		profile = &cliContext{}
+		cfg.Contexts[name] = profile
+	}
+
+	fields := map[string]*string{
+		baseURLFlag:    &profile.BaseURL,
+		authMethodFlag: &profile.AuthMethod,
+		accessKeyFlag:  &profile.AccessKey,
+		secretKeyFlag:  &profile.SecretKey,
+		emailFlag:      &profile.Email,
+		passwordFlag:   &profile.Password,
+		accountFlag:    &profile.Account,
+		caCertFlag:     &profile.CACert,
+		outputFlag:     &profile.Output,
+	}
+	for flag, field := range fields {
+		if cmd.IsSet(flag) {
+			*field = cmd.String(flag)
+		}
+	}
+
+	if cmd.Bool(useFlag) || cfg.CurrentContext == "" {
+		cfg.CurrentContext = name
+	}
+
+	if err := cfg.save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Root().Writer, "context %q saved\n", name)
+	return nil
+}
+
+func useContextAction(ctx context.Context, cmd *cli.Command) error {
+	name := cmd.Args().First()
+	if name == "" {
+		return fmt.Errorf("context name must be provided as the first argument")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cfg.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found in config", name)
+	}
+
+	cfg.CurrentContext = name
+	if err := cfg.save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Root().Writer, "switched to context %q\n", name)
+	return nil
+}
+
+type contextSummary struct {
+	Name       string `json:"name"`
+	Current    bool   `json:"current"`
+	BaseURL    string `json:"base_url"`
+	AuthMethod string `json:"auth_method,omitempty"`
+	Account    string `json:"account,omitempty"`
+	CACert     string `json:"ca_cert,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+func listContextsAction(ctx context.Context, cmd *cli.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	summaries := make([]contextSummary, 0, len(names))
+	for _, name := range names {
+		profile := cfg.Contexts[name]
+		summaries = append(summaries, contextSummary{
+			Name:       name,
+			Current:    name == cfg.CurrentContext,
+			BaseURL:    profile.BaseURL,
+			AuthMethod: profile.AuthMethod,
+			Account:    profile.Account,
+			CACert:     profile.CACert,
+			Output:     profile.Output,
+		})
+	}
+
+	return WriteValueToRoot(ctx, cmd, summaries)
+}
+
+// saveContextAccount records account as the account of the named context,
+// so that later invocations use it too.
+func saveContextAccount(name, account string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile, ok := cfg.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q not found in config", name)
+	}
+
+	profile.Account = account
+	return cfg.save()
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// isolateConfig unsets the LANDSCAPE_ env vars for the test and points
+// LANDSCAPE_CONFIG at a config file in a temporary directory, returning
+// its path.
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, "LANDSCAPE_") {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("LANDSCAPE_CONFIG", path)
+	return path
+}
+
+// pristineFlags holds copies of the subcommands' flags as they are before
+// any command has run. The subcommands are package variables, and their
+// flags remember being set, so runCLI restores them before each run.
+var pristineFlags = map[*cli.Command][]cli.Flag{}
+
+func init() {
+	var snapshot func(cmds []*cli.Command)
+	snapshot = func(cmds []*cli.Command) {
+		for _, cmd := range cmds {
+			pristineFlags[cmd] = copyFlags(cmd.Flags)
+			snapshot(cmd.Commands)
+		}
+	}
+	snapshot(newRootCommand().Commands)
+}
+
+// copyFlags returns shallow copies of the flags.
+func copyFlags(flags []cli.Flag) []cli.Flag {
+	copies := make([]cli.Flag, 0, len(flags))
+	for _, f := range flags {
+		v := reflect.ValueOf(f)
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(v.Elem())
+		copies = append(copies, c.Interface().(cli.Flag))
+	}
+	return copies
+}
+
+// runCLI runs the landscape-api command with the given arguments,
+// returning what it wrote.
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	for cmd, flags := range pristineFlags {
+		cmd.Flags = copyFlags(flags)
+	}
+
+	var out bytes.Buffer
+	cmd := newRootCommand()
+	cmd.Writer = &out
+	cmd.ErrWriter = &out
+
+	err := cmd.Run(context.Background(), append([]string{"landscape-api"}, args...))
+	return out.String(), err
+}
+
+func TestResolveConnection(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		want    connection
+		wantErr bool
+	}{
+		{
+			name: "current context",
+			want: connection{contextName: "prod", baseURL: "https://prod.example.com", authMethod: "access-key", accessKey: "prod-ak", secretKey: "prod-sk", output: "table"},
+		},
+		{
+			name: "env overrides the context",
+			env:  map[string]string{"LANDSCAPE_BASE_URL": "https://env.example.com", "LANDSCAPE_ACCESS_KEY": "env-ak"},
+			want: connection{contextName: "prod", baseURL: "https://env.example.com", authMethod: "access-key", accessKey: "env-ak", secretKey: "prod-sk", output: "table"},
+		},
+		{
+			name: "flag overrides env",
+			env:  map[string]string{"LANDSCAPE_BASE_URL": "https://env.example.com"},
+			args: []string{"-base-url", "https://flag.example.com"},
+			want: connection{contextName: "prod", baseURL: "https://flag.example.com", authMethod: "access-key", accessKey: "prod-ak", secretKey: "prod-sk", output: "table"},
+		},
+		{
+			name: "context flag",
+			args: []string{"-context", "dev"},
+			want: connection{contextName: "dev", baseURL: "http://localhost:8080", email: "dev@example.com", password: "dev-password"},
+		},
+		{
+			name: "context env",
+			env:  map[string]string{"LANDSCAPE_CONTEXT": "dev"},
+			want: connection{contextName: "dev", baseURL: "http://localhost:8080", email: "dev@example.com", password: "dev-password"},
+		},
+		{
+			name: "context flag overrides context env",
+			env:  map[string]string{"LANDSCAPE_CONTEXT": "dev"},
+			args: []string{"-context", "prod"},
+			want: connection{contextName: "prod", baseURL: "https://prod.example.com", authMethod: "access-key", accessKey: "prod-ak", secretKey: "prod-sk", output: "table"},
+		},
+		{
+			name:    "unknown context",
+			args:    []string{"-context", "staging"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := isolateConfig(t)
+			config := `current-context: prod
+contexts:
+  prod:
+    base-url: https://prod.example.com
+    auth-method: access-key
+    access-key: prod-ak
+    secret-key: prod-sk
+    output: table
+  dev:
+    base-url: http://localhost:8080
+    email: dev@example.com
+    password: dev-password
+`
+			if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var conn *connection
+			cmd := newRootCommand()
+			cmd.Before = nil
+			cmd.Commands = nil
+			cmd.Action = func(ctx context.Context, c *cli.Command) error {
+				var err error
+				conn, err = resolveConnection(c)
+				return err
+			}
+
+			err := cmd.Run(context.Background(), append([]string{"landscape-api"}, tt.args...))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveConnection failed: %v", err)
+			}
+			if *conn != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, *conn)
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	isolateConfig(t)
+
+	t.Run("LANDSCAPE_CONFIG", func(t *testing.T) {
+		t.Setenv("LANDSCAPE_CONFIG", "/etc/landscape/config.yaml")
+
+		path, err := configPath()
+		if err != nil {
+			t.Fatalf("configPath failed: %v", err)
+		}
+		if path != "/etc/landscape/config.yaml" {
+			t.Errorf("expected /etc/landscape/config.yaml, got %s", path)
+		}
+	})
+
+	t.Run("user config directory", func(t *testing.T) {
+		t.Setenv("LANDSCAPE_CONFIG", "")
+		dir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		t.Setenv("HOME", dir)
+		t.Setenv("AppData", dir)
+
+		want, err := os.UserConfigDir()
+		if err != nil {
+			t.Skipf("no user config directory: %v", err)
+		}
+		want = filepath.Join(want, "landscape-api", "config.yaml")
+
+		path, err := configPath()
+		if err != nil {
+			t.Fatalf("configPath failed: %v", err)
+		}
+		if path != want {
+			t.Errorf("expected %s, got %s", want, path)
+		}
+	})
+}
+
+func TestConfigSaveMode(t *testing.T) {
+	path := isolateConfig(t)
+	path = filepath.Join(filepath.Dir(path), "landscape-api", "config.yaml")
+	t.Setenv("LANDSCAPE_CONFIG", path)
+
+	cfg := &cliConfig{Contexts: map[string]*cliContext{"prod": {SecretKey: "prod-sk"}}}
+	if err := cfg.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+
+	dirInfo, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirInfo.Mode().Perm() != 0o700 {
+		t.Errorf("expected directory mode 0700, got %o", dirInfo.Mode().Perm())
+	}
+
+	// An existing file that others can read is restricted when saved.
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if info, err = os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600 after saving again, got %o", info.Mode().Perm())
+	}
+}
+
+func TestSetAndUseContext(t *testing.T) {
+	isolateConfig(t)
+
+	if _, err := runCLI(t, "config", "set-context", "prod", "-base-url", "https://prod.example.com", "-access-key", "prod-ak", "-secret-key", "prod-sk"); err != nil {
+		t.Fatalf("set-context prod failed: %v", err)
+	}
+	if _, err := runCLI(t, "config", "set-context", "dev", "-base-url", "http://localhost:8080", "-output", "yaml"); err != nil {
+		t.Fatalf("set-context dev failed: %v", err)
+	}
+	// Only the given flags are changed.
+	if _, err := runCLI(t, "config", "set-context", "prod", "-account", "ops"); err != nil {
+		t.Fatalf("updating prod failed: %v", err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.CurrentContext != "prod" {
+		t.Errorf("expected the first context to be current, got %q", cfg.CurrentContext)
+	}
+	wantProd := cliContext{BaseURL: "https://prod.example.com", AccessKey: "prod-ak", SecretKey: "prod-sk", Account: "ops"}
+	if got := *cfg.Contexts["prod"]; got != wantProd {
+		t.Errorf("expected prod to be %+v, got %+v", wantProd, got)
+	}
+	wantDev := cliContext{BaseURL: "http://localhost:8080", Output: "yaml"}
+	if got := *cfg.Contexts["dev"]; got != wantDev {
+		t.Errorf("expected dev to be %+v, got %+v", wantDev, got)
+	}
+
+	out, err := runCLI(t, "config", "use-context", "dev")
+	if err != nil {
+		t.Fatalf("use-context failed: %v", err)
+	}
+	if out != "switched to context \"dev\"\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if cfg, err = loadConfig(); err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.CurrentContext != "dev" {
+		t.Errorf("expected dev to be current, got %q", cfg.CurrentContext)
+	}
+
+	if _, err := runCLI(t, "config", "use-context", "staging"); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+	if _, err := runCLI(t, "config", "set-context", "staging", "-auth-method", "kerberos"); err == nil {
+		t.Error("expected an error for an unknown auth method")
+	}
+	if cfg, err = loadConfig(); err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.CurrentContext != "dev" || len(cfg.Contexts) != 2 {
+		t.Errorf("expected failed commands to leave the config as it was, got %+v", cfg)
+	}
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffOp is a line of a diff: kept, removed from the old text or added by
+// the new one.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the differences between the old and new text in the
+// unified format used by diff -u, or "" if they're the same.
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	oldLines, newLines := diffTextLines(oldText), diffTextLines(newText)
+	ops := diffLines(oldLines, newLines)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	// oldLine and newLine are the line numbers ops[i] is at.
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// A hunk starts diffContext lines before the change and runs
+		// until there are more than twice that many unchanged lines.
+		start := max(i-diffContext, 0)
+		for ; i > start && ops[i-1].kind == ' '; i-- {
+			oldLine--
+			newLine--
+		}
+
+		end, unchanged := i, 0
+		for ; end < len(ops) && unchanged <= 2*diffContext; end++ {
+			if ops[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		end -= max(unchanged-diffContext, 0)
+
+		var oldCount, newCount int
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+
+		for _, op := range ops[i:end] {
+			fmt.Fprintf(&b, "%c%s\n", op.kind, op.line)
+		}
+
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+
+	return b.String()
+}
+
+// hunkRange formats the start and length of a hunk, where an empty range
+// starts at the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	default:
+		return fmt.Sprintf("%d,%d", start, count)
+	}
+}
+
+// splitLines splits text into lines, without a trailing empty line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffTextLines splits text into the lines to diff. Like diff -u, a last
+// line without a newline is followed by a note saying so, which also makes
+// it differ from the same line with one.
+func diffTextLines(text string) []string {
+	lines := splitLines(text)
+	if len(lines) > 0 && !strings.HasSuffix(text, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
+
+// diffLines returns the edits turning a into b, from their longest common
+// subsequence. Scripts are small enough for its quadratic cost.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
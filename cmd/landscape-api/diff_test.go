@@ -0,0 +1,165 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns the lines 1 to n, with the lines in changed
+// replaced by "x".
+func numberedLines(n int, changed ...int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if slices.Contains(changed, i) {
+			b.WriteString("x\n")
+		} else {
+			b.WriteString(strconv.Itoa(i) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name             string
+		oldText, newText string
+		want             string
+	}{
+		{
+			name:    "identical",
+			oldText: numberedLines(5),
+			newText: numberedLines(5),
+			want:    "",
+		},
+		{
+			name:    "insert into an empty file",
+			oldText: "",
+			newText: "a\nb\n",
+			want:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "delete everything",
+			oldText: "a\nb\n",
+			newText: "",
+			want:    "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "insert",
+			oldText: numberedLines(10),
+			newText: strings.Replace(numberedLines(10), "5\n", "5\nnew\n", 1),
+			want:    "@@ -3,6 +3,7 @@\n 3\n 4\n 5\n+new\n 6\n 7\n 8\n",
+		},
+		{
+			name:    "delete",
+			oldText: numberedLines(10),
+			newText: strings.Replace(numberedLines(10), "5\n", "", 1),
+			want:    "@@ -2,7 +2,6 @@\n 2\n 3\n 4\n-5\n 6\n 7\n 8\n",
+		},
+		{
+			name:    "insert at the start of the file",
+			oldText: "b\nc\n",
+			newText: "a\nb\nc\n",
+			want:    "@@ -1,2 +1,3 @@\n+a\n b\n c\n",
+		},
+		{
+			name:    "change at the start of the file",
+			oldText: numberedLines(10),
+			newText: numberedLines(10, 1),
+			want:    "@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n",
+		},
+		{
+			name:    "change at the end of the file",
+			oldText: numberedLines(10),
+			newText: numberedLines(10, 10),
+			want:    "@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+x\n",
+		},
+		{
+			name:    "nearby changes share a hunk",
+			oldText: numberedLines(12),
+			newText: numberedLines(12, 3, 9),
+			want:    "@@ -1,12 +1,12 @@\n 1\n 2\n-3\n+x\n 4\n 5\n 6\n 7\n 8\n-9\n+x\n 10\n 11\n 12\n",
+		},
+		{
+			name:    "distant changes have their own hunks",
+			oldText: numberedLines(20),
+			newText: numberedLines(20, 2, 15),
+			want: "@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n" +
+				"@@ -12,7 +12,7 @@\n 12\n 13\n 14\n-15\n+x\n 16\n 17\n 18\n",
+		},
+		{
+			name:    "newline added at the end of the file",
+			oldText: "a\nb",
+			newText: "a\nb\n",
+			want:    "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:    "newline removed from the end of the file",
+			oldText: "a\n",
+			newText: "a",
+			want:    "@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "change before a last line without a newline",
+			oldText: "a\nb",
+			newText: "x\nb",
+			want:    "@@ -1,2 +1,2 @@\n-a\n+x\n b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff("old", "new", tt.oldText, tt.newText)
+			if tt.want != "" {
+				tt.want = "--- old\n+++ new\n" + tt.want
+			}
+			if got != tt.want {
+				t.Errorf("expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []diffOp
+	}{
+		{
+			name: "empty",
+			want: []diffOp{},
+		},
+		{
+			name: "identical",
+			a:    []string{"a", "b"},
+			b:    []string{"a", "b"},
+			want: []diffOp{{' ', "a"}, {' ', "b"}},
+		},
+		{
+			name: "insert",
+			b:    []string{"a"},
+			want: []diffOp{{'+', "a"}},
+		},
+		{
+			name: "delete",
+			a:    []string{"a"},
+			want: []diffOp{{'-', "a"}},
+		},
+		{
+			name: "deletions come before insertions",
+			a:    []string{"a", "b", "c"},
+			b:    []string{"a", "x", "c", "d"},
+			want: []diffOp{{' ', "a"}, {'-', "b"}, {'+', "x"}, {' ', "c"}, {'+', "d"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.a, tt.b); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+const allFlag = "all"
+
+// tokenCache returns the cache that keeps tokens between invocations. It
+// is set by LANDSCAPE_TOKEN_CACHE or kept next to the config file.
+func tokenCache() (*client.FileTokenCache, error) {
+	if path := os.Getenv("LANDSCAPE_TOKEN_CACHE"); path != "" {
+		return client.NewFileTokenCache(path), nil
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewFileTokenCache(filepath.Join(filepath.Dir(path), "tokens.json")), nil
+}
+
+var loginCmd = &cli.Command{
+	Name:   "login",
+	Usage:  "Log in and cache the token, so that later commands don't need to log in.",
+	Action: loginAction,
+}
+
+var logoutCmd = &cli.Command{
+	Name:  "logout",
+	Usage: "Remove the cached token for the current connection.",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  allFlag,
+			Usage: "Remove the cached tokens for every connection.",
+		},
+	},
+	Action: logoutAction,
+}
+
+func loginAction(ctx context.Context, cmd *cli.Command) error {
+	conn, err := resolveConnection(cmd.Root())
+	if err != nil {
+		return err
+	}
+
+	if err := forgetToken(conn); err != nil {
+		return err
+	}
+
+	if _, err := connect(ctx, cmd.Root(), conn); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Root().Writer, "logged in to %s\n", conn.baseURL)
+	return nil
+}
+
+func logoutAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool(allFlag) {
+		cache, err := tokenCache()
+		if err != nil {
+			return err
+		}
+
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.Root().Writer, "removed all cached tokens")
+		return nil
+	}
+
+	conn, err := resolveConnection(cmd.Root())
+	if err != nil {
+		return err
+	}
+
+	if err := forgetToken(conn); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Root().Writer, "logged out of %s\n", conn.baseURL)
+	return nil
+}
+
+// forgetToken removes the cached token for the connection.
+func forgetToken(conn *connection) error {
+	if conn.baseURL == "" {
+		return fmt.Errorf("base URL must be provided")
+	}
+
+	_, identity, secret := loginProvider(conn)
+
+	cache, err := tokenCache()
+	if err != nil {
+		return err
+	}
+
+	return cache.Delete(client.TokenCacheKey(conn.baseURL, conn.account, identity, secret))
+}
@@ -3,14 +3,14 @@
 package main
 
 import (
-	"bytes"
+	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
+	"strings"
 
 	"github.com/jansdhillon/landscape-go-api-client/client"
 	"github.com/urfave/cli/v3"
@@ -27,22 +27,58 @@ const (
 	emailFlag     = "email"
 	passwordFlag  = "password"
 	accountFlag   = "account"
+
+	caCertFlag             = "ca-cert"
+	insecureSkipVerifyFlag = "insecure-skip-verify"
+	timeoutFlag            = "timeout"
+	proxyFlag              = "proxy"
+	maxAttachmentSizeFlag  = "max-attachment-size"
 )
 
 func main() {
-	cmd := &cli.Command{
-		Name:  "landscape-api",
-		Usage: "Interact with the Landscape API.",
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := newRootCommand().Run(ctx, os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newRootCommand returns the landscape-api command with all of its
+// subcommands.
+func newRootCommand() *cli.Command {
+	return &cli.Command{
+		Name:                            "landscape-api",
+		Usage:                           "Interact with the Landscape API.",
+		EnableShellCompletion:           true,
+		ConfigureShellCompletionCommand: configureCompletionCommand,
 		Commands: []*cli.Command{
+			accountCmd,
+			apiCmd,
+			callCmd,
+			configCmd,
+			loginCmd,
+			logoutCmd,
 			scriptCmd,
+			scriptProfileCmd,
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     baseURLFlag,
-				Aliases:  []string{"u", "url", "base_url"},
-				Usage:    "The base URL of Landscape (can also be set via LANDSCAPE_BASE_URL env var).",
-				Required: true,
-				Sources:  cli.EnvVars("LANDSCAPE_BASE_URL"),
+				Name:    contextFlag,
+				Usage:   "The name of the context from the config file to connect with, instead of the current context (can also be set via LANDSCAPE_CONTEXT env var).",
+				Sources: cli.EnvVars("LANDSCAPE_CONTEXT"),
+			},
+			&cli.StringFlag{
+				Name:    outputFlag,
+				Aliases: []string{"o"},
+				Usage:   "The output format: json, yaml, table, wide, raw, jsonpath=<expr> or go-template=<template> (can also be set via LANDSCAPE_OUTPUT env var or the selected context). Defaults to json.",
+				Sources: cli.EnvVars("LANDSCAPE_OUTPUT"),
+			},
+			&cli.StringFlag{
+				Name:    baseURLFlag,
+				Aliases: []string{"u", "url", "base_url"},
+				Usage:   "The base URL of Landscape (can also be set via LANDSCAPE_BASE_URL env var or the selected context).",
+				Sources: cli.EnvVars("LANDSCAPE_BASE_URL"),
 			},
 			&cli.StringFlag{
 				Name:    accessKeyFlag,
@@ -71,71 +107,163 @@ func main() {
 			&cli.StringFlag{
 				Name:    accountFlag,
 				Aliases: []string{"a"},
-				Usage:   "An account to login into the Landscape API with (can also be set via LANDSCAPE_ACCOUNT env var). If provided, you must also provide the -email and -password flags or set the LANDSCAPE_EMAIL and LANDSCAPE_PASSWORD env vars.",
+				Usage:   "An account to login into the Landscape API with (can also be set via LANDSCAPE_ACCOUNT env var). With an access key, the client switches to this account after logging in.",
 				Sources: cli.EnvVars("LANDSCAPE_ACCOUNT"),
 			},
+			&cli.StringFlag{
+				Name:    caCertFlag,
+				Usage:   "A PEM file of CA certificates to trust when connecting to a self-hosted Landscape (can also be set via LANDSCAPE_CA_CERT env var).",
+				Sources: cli.EnvVars("LANDSCAPE_CA_CERT"),
+			},
+			&cli.BoolFlag{
+				Name:    insecureSkipVerifyFlag,
+				Usage:   "Don't verify the Landscape server's TLS certificate. Only use this for testing.",
+				Sources: cli.EnvVars("LANDSCAPE_INSECURE_SKIP_VERIFY"),
+			},
+			&cli.DurationFlag{
+				Name:    timeoutFlag,
+				Usage:   "The maximum time to wait for each request, such as 30s (can also be set via LANDSCAPE_TIMEOUT env var). Zero means no timeout.",
+				Sources: cli.EnvVars("LANDSCAPE_TIMEOUT"),
+			},
+			&cli.StringFlag{
+				Name:    proxyFlag,
+				Usage:   "A proxy URL to send requests through, overriding HTTPS_PROXY (can also be set via LANDSCAPE_PROXY env var).",
+				Sources: cli.EnvVars("LANDSCAPE_PROXY"),
+			},
+			&cli.Int64Flag{
+				Name:    maxAttachmentSizeFlag,
+				Usage:   "The largest script attachment to upload, in bytes, for servers that accept larger attachments (can also be set via LANDSCAPE_MAX_ATTACHMENT_SIZE env var).",
+				Value:   client.DefaultMaxAttachmentSize,
+				Sources: cli.EnvVars("LANDSCAPE_MAX_ATTACHMENT_SIZE"),
+			},
 		},
 		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
-			baseURL := c.String(baseURLFlag)
-			if baseURL == "" {
-				return ctx, fmt.Errorf("base URL must be provided")
+			client.MaxAttachmentSize = c.Int64(maxAttachmentSizeFlag)
+
+			if offlineCommands[c.Args().First()] {
+				return ctx, nil
 			}
 
-			email := c.String(emailFlag)
-			password := c.String(passwordFlag)
-			account := c.String(accountFlag)
+			conn, err := resolveConnection(c)
+			if err != nil {
+				return ctx, err
+			}
 
-			var lp client.LoginProvider
+			return connect(ctx, c, conn)
+		},
+	}
+}
 
-			if email != "" && password != "" {
-				if account != "" {
-					lp = client.NewEmailPasswordProvider(email, password, &account)
-				} else {
-					lp = client.NewEmailPasswordProvider(email, password, nil)
-				}
+// connect logs in to Landscape with the resolved connection, reusing a
+// cached token when there is one, and returns a context holding the client.
+func connect(ctx context.Context, c *cli.Command, conn *connection) (context.Context, error) {
+	if conn.baseURL == "" {
+		return ctx, fmt.Errorf("base URL must be provided")
+	}
 
-			} else {
-				accessKey := c.String(accessKeyFlag)
-				secretKey := c.String(secretKeyFlag)
+	lp, identity, secret := loginProvider(conn)
 
-				if accessKey == "" || secretKey == "" {
-					return ctx, fmt.Errorf("must provide the -e & -p flags or the -ak & -sk flags, or set either the LANDSCAPE_EMAIL & LANDSCAPE_PASSWORD env vars or the LANDSCAPE_ACCESS_KEY & LANDSCAPE_SECRET_KEY env vars")
-				}
+	cache, err := tokenCache()
+	if err != nil {
+		return ctx, err
+	}
 
-				lp = client.NewAccessKeyProvider(accessKey, secretKey)
-			}
+	opts := append(clientOptions(c, conn),
+		client.WithTokenCache(cache, client.TokenCacheKey(conn.baseURL, conn.account, identity, secret)),
+	)
+	if conn.account != "" {
+		opts = append(opts, client.WithAccount(conn.account))
+	}
 
-			api, err := client.NewLandscapeAPIClient(baseURL, lp)
-			if err != nil {
-				return ctx, err
-			}
+	api, err := client.NewLandscapeAPIClientWithContext(ctx, conn.baseURL, lp, opts...)
+	if errors.Is(err, client.ErrMissingCredentials) {
+		return ctx, fmt.Errorf("must provide the -e & -p flags or the -ak & -sk flags, or set either the LANDSCAPE_EMAIL & LANDSCAPE_PASSWORD env vars, the LANDSCAPE_ACCESS_KEY & LANDSCAPE_SECRET_KEY env vars or the LANDSCAPE_TOKEN env var")
+	}
+	if err != nil {
+		return ctx, err
+	}
 
-			return context.WithValue(ctx, apiClientKey, api), nil
-		},
+	ctx = context.WithValue(ctx, configContextKey, conn.contextName)
+	ctx = context.WithValue(ctx, outputKey, conn.output)
+	return context.WithValue(ctx, apiClientKey, api), nil
+}
+
+// loginProvider returns a provider that logs in with the first of a token
+// in LANDSCAPE_TOKEN, the email and password, or the access key that was
+// provided. A context's auth method limits it to one of them. The identity
+// returned is what its tokens are cached under.
+func loginProvider(conn *connection) (client.LoginProvider, string, string) {
+	var account *string
+	if conn.account != "" {
+		account = &conn.account
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	token := os.Getenv(client.TokenEnvVar)
+	providers := []client.LoginProvider{
+		client.NewEnvTokenProvider(),
+		client.NewEmailPasswordProvider(conn.email, conn.password, account),
+		client.NewAccessKeyProvider(conn.accessKey, conn.secretKey),
+	}
+	identities := []string{token, conn.email, conn.accessKey}
+	secrets := []string{"", conn.password, conn.secretKey}
 
-	if err := cmd.Run(ctx, os.Args); err != nil {
-		log.Fatal(err)
+	switch conn.authMethod {
+	case authMethodToken:
+		providers, identities, secrets = providers[:1], identities[:1], secrets[:1]
+	case authMethodPassword:
+		providers, identities, secrets = providers[1:2], identities[1:2], secrets[1:2]
+	case authMethodAccessKey:
+		providers, identities, secrets = providers[2:], identities[2:], secrets[2:]
 	}
+
+	return client.NewChainProvider(providers...), strings.Join(identities, "\x00"), strings.Join(secrets, "\x00")
 }
 
-func WriteResponseToRoot(_ context.Context, cmd *cli.Command, res *http.Response) error {
-	defer res.Body.Close()
+// offlineCommands are the top-level commands that don't call the API, so
+// the root command doesn't log in for them.
+var offlineCommands = map[string]bool{
+	"completion": true,
+	"config":     true,
+	"help":       true,
+	"h":          true,
+	"login":      true,
+	"logout":     true,
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
+// clientOptions returns the client options set by the root command's
+// transport flags and the resolved connection.
+func clientOptions(c *cli.Command, conn *connection) []client.Option {
+	opts := []client.Option{
+		client.WithUserAgent("landscape-api"),
+		client.WithTimeout(c.Duration(timeoutFlag)),
+		client.WithInsecureSkipVerify(c.Bool(insecureSkipVerifyFlag)),
+	}
+
+	if caCert := conn.caCert; caCert != "" {
+		opts = append(opts, client.WithCACertFile(caCert))
 	}
 
-	var out bytes.Buffer
-	if err := json.Indent(&out, body, "", "  "); err != nil {
-		return err
+	if proxy := c.String(proxyFlag); proxy != "" {
+		opts = append(opts, client.WithProxy(proxy))
 	}
 
-	out.WriteTo(cmd.Root().Writer)
-	fmt.Fprintln(cmd.Root().Writer)
-	return nil
+	return opts
+}
+
+// confirm asks the user a yes/no question, returning true only if they
+// answer yes.
+func confirm(in *bufio.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+
+	answer, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
 }
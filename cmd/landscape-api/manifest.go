@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/jansdhillon/landscape-go-api-client/internal/manifest"
+	"github.com/urfave/cli/v3"
+)
+
+const pruneFlag = "prune"
+
+// manifestFlags returns the flags shared by script apply and script diff.
+func manifestFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     fileFlag,
+			Aliases:  []string{"f"},
+			Usage:    "A manifest file, or a directory of .yaml and .yml manifests.",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  pruneFlag,
+			Usage: "Archive the active V2 scripts that no manifest matches.",
+		},
+	}
+}
+
+const manifestDescription = `Each manifest is a YAML document describing a script, matched to an
+existing script by its id if it has one, or else by its title:
+
+   id: 21433
+   title: backup
+   code_file: backup.sh
+   interpreter: /bin/bash
+   time_limit: 300
+   username: root
+   access_group: global
+   attachments:
+     - backup.conf
+
+Paths are relative to the manifest. Fields that are left out aren't
+managed, and listing attachments, even none, removes those not listed.`
+
+var applyScriptsCmd = &cli.Command{
+	Name:        "apply",
+	Usage:       "Create, edit and archive scripts to match a set of manifests.",
+	Description: manifestDescription,
+	Flags: append([]cli.Flag{
+		&cli.BoolFlag{
+			Name:    yesFlag,
+			Aliases: []string{"y"},
+			Usage:   "Don't ask for confirmation.",
+		},
+	}, manifestFlags()...),
+	Action: applyScriptsAction,
+}
+
+var diffScriptsCmd = &cli.Command{
+	Name:        "diff",
+	Usage:       "Show the changes script apply would make, without making them.",
+	Description: manifestDescription,
+	Flags:       manifestFlags(),
+	Action:      diffScriptsAction,
+}
+
+func diffScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	plan, err := manifestPlan(ctx, cmd, api)
+	if err != nil {
+		return err
+	}
+
+	writePlan(cmd.Root().Writer, plan)
+	return nil
+}
+
+func applyScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	plan, err := manifestPlan(ctx, cmd, api)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.Root().Writer
+	writePlan(out, plan)
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	if !cmd.Bool(yesFlag) {
+		ok, err := confirm(bufio.NewReader(cmd.Root().Reader), out, "Do you want to apply these changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(out, "no changes were made")
+			return nil
+		}
+	}
+
+	return plan.Apply(ctx, api.Scripts(), func(c manifest.Change, script client.Script) {
+		switch c.Action {
+		case manifest.Create:
+			fmt.Fprintf(out, "created script %d %q\n", script.GetID(), script.GetTitle())
+		case manifest.Edit:
+			fmt.Fprintf(out, "edited script %d %q\n", script.GetID(), script.GetTitle())
+		case manifest.Archive:
+			fmt.Fprintf(out, "archived script %d %q\n", c.Script.GetID(), c.Script.GetTitle())
+		}
+	})
+}
+
+// manifestPlan loads the manifests given with -file and plans the changes
+// that make the server's scripts match them.
+func manifestPlan(ctx context.Context, cmd *cli.Command, api *client.ClientWithResponses) (*manifest.Plan, error) {
+	manifests, err := manifest.Load(cmd.String(fileFlag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifests: %w", err)
+	}
+
+	return manifest.NewPlan(ctx, api.Scripts(), manifests, cmd.Bool(pruneFlag))
+}
+
+// writePlan describes each change of the plan, with the differences in
+// the code of edited scripts, followed by a summary.
+func writePlan(w io.Writer, plan *manifest.Plan) {
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(w, "No changes. The scripts match the manifests.")
+		return
+	}
+
+	for _, c := range plan.Changes {
+		switch c.Action {
+		case manifest.Create:
+			m := c.Manifest
+			fmt.Fprintf(w, "+ create %q from %s\n", m.Title, m.Path)
+			if m.TimeLimit != nil {
+				fmt.Fprintf(w, "    time limit: %d\n", *m.TimeLimit)
+			}
+			if m.Username != nil {
+				fmt.Fprintf(w, "    username: %q\n", *m.Username)
+			}
+			if m.AccessGroup != nil {
+				fmt.Fprintf(w, "    access group: %q\n", *m.AccessGroup)
+			}
+			writeAttachmentChanges(w, c)
+
+		case manifest.Edit:
+			s, edit := c.Script, c.Edit
+			fmt.Fprintf(w, "~ edit %q (script %d) from %s\n", s.GetTitle(), s.GetID(), c.Manifest.Path)
+			if edit.Title != nil {
+				fmt.Fprintf(w, "    title: %q -> %q\n", s.GetTitle(), *edit.Title)
+			}
+			if edit.TimeLimit != nil {
+				fmt.Fprintf(w, "    time limit: %d -> %d\n", s.GetTimeLimit(), *edit.TimeLimit)
+			}
+			if edit.Username != nil {
+				fmt.Fprintf(w, "    username: %q -> %q\n", s.GetUsername(), *edit.Username)
+			}
+			if edit.AccessGroup != nil {
+				fmt.Fprintf(w, "    access group: %q -> %q\n", s.GetAccessGroup(), *edit.AccessGroup)
+			}
+			writeAttachmentChanges(w, c)
+			if edit.Code != nil {
+				fmt.Fprintln(w, "    code:")
+				diff := unifiedDiff(fmt.Sprintf("script/%d", s.GetID()), c.Manifest.CodeFile, c.Code, *edit.Code)
+				for _, line := range splitLines(diff) {
+					fmt.Fprintf(w, "      %s\n", line)
+				}
+			}
+
+		case manifest.Archive:
+			fmt.Fprintf(w, "- archive %q (script %d)\n", c.Script.GetTitle(), c.Script.GetID())
+		}
+	}
+
+	summary := []string{
+		fmt.Sprintf("%d to create", plan.Count(manifest.Create)),
+		fmt.Sprintf("%d to edit", plan.Count(manifest.Edit)),
+		fmt.Sprintf("%d to archive", plan.Count(manifest.Archive)),
+	}
+	fmt.Fprintf(w, "\nPlan: %s.\n", strings.Join(summary, ", "))
+}
+
+func writeAttachmentChanges(w io.Writer, c manifest.Change) {
+	for _, filename := range c.RemoveAttachments {
+		fmt.Fprintf(w, "    - attachment %s\n", filename)
+	}
+	for _, f := range c.AddAttachments {
+		fmt.Fprintf(w, "    + attachment %s\n", f.Name)
+	}
+}
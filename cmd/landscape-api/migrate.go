@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+const archiveFlag = "archive"
+
+var migrateScriptsCmd = &cli.Command{
+	Name:      "migrate",
+	Usage:     "Create a V2 script from each of the given V1 scripts, keeping their code, attachments and settings.",
+	ArgsUsage: "[script-id...]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  allFlag,
+			Usage: "Migrate every V1 script, rather than those given as arguments.",
+		},
+		&cli.BoolFlag{
+			Name:  archiveFlag,
+			Usage: "Archive each V1 script once it has been migrated with all of its attachments. Scripts the server won't archive are reported as failed, with the V2 script they were migrated to.",
+		},
+		&cli.BoolFlag{
+			Name:    yesFlag,
+			Aliases: []string{"y"},
+			Usage:   "Don't ask for confirmation before archiving.",
+		},
+	},
+	Action:        migrateScriptsAction,
+	ShellComplete: completeScriptIDs,
+}
+
+// scriptMigrationResult is a row of the migration report: the migration
+// of one script, or why it failed.
+type scriptMigrationResult struct {
+	client.ScriptMigration
+	Error string `json:"error,omitempty"`
+}
+
+func migrateScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	var ids []int
+	if cmd.Bool(allFlag) {
+		if cmd.Args().Present() {
+			return fmt.Errorf("-%s can't be combined with script IDs", allFlag)
+		}
+
+		for script, err := range api.Scripts().All(ctx, client.ListScriptsParams{ScriptType: client.ScriptTypeV1}) {
+			if err != nil {
+				return fmt.Errorf("failed to list V1 scripts: %w", err)
+			}
+			ids = append(ids, script.GetID())
+		}
+		if len(ids) == 0 {
+			fmt.Fprintln(cmd.Root().ErrWriter, "no V1 scripts to migrate")
+			return nil
+		}
+	} else {
+		var err error
+		if ids, err = scriptIDArgs(cmd); err != nil {
+			return fmt.Errorf("%w, or -%s to migrate every V1 script", err, allFlag)
+		}
+	}
+
+	archive := cmd.Bool(archiveFlag)
+	if archive && !cmd.Bool(yesFlag) {
+		out := cmd.Root().Writer
+		ok, err := confirm(bufio.NewReader(cmd.Root().Reader), out, fmt.Sprintf("Do you want to archive %d V1 scripts once they are migrated?", len(ids)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(out, "skipped migration")
+			return nil
+		}
+	}
+
+	results := make([]scriptMigrationResult, 0, len(ids))
+	failed := 0
+	for _, id := range ids {
+		fmt.Fprintf(cmd.Root().ErrWriter, "migrating script %d...\n", id)
+
+		migration, err := api.Scripts().Migrate(ctx, id, client.MigrateScriptParams{Archive: archive})
+
+		result := scriptMigrationResult{ScriptMigration: client.ScriptMigration{V1ID: id}}
+		if migration != nil {
+			result.ScriptMigration = *migration
+		}
+		if err != nil {
+			failed++
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	if err := WriteValueToRoot(ctx, cmd, results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to migrate %d of %d scripts", failed, len(ids))
+	}
+
+	return nil
+}
@@ -0,0 +1,591 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/jansdhillon/landscape-go-api-client/internal/bundle"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const outputKey ctxKey = "landscape-output"
+
+const defaultOutput = "json"
+
+// printer writes a value in one output format. raw is the value's JSON
+// encoding, and v is the decoded Go value when it is known.
+type printer func(w io.Writer, v any, raw []byte) error
+
+// outputPrinter returns the printer for the output format set by the -o
+// flag, falling back to the selected context's format and then to JSON.
+func outputPrinter(ctx context.Context, cmd *cli.Command) (printer, error) {
+	format := defaultOutput
+	if f, ok := ctx.Value(outputKey).(string); ok && f != "" {
+		format = f
+	}
+	if root := cmd.Root(); root.IsSet(outputFlag) {
+		format = root.String(outputFlag)
+	}
+
+	return parseOutput(format)
+}
+
+// parseOutput returns the printer for format, which is one of json, yaml,
+// table, wide, raw, jsonpath=<expr> or go-template=<template>.
+func parseOutput(format string) (printer, error) {
+	name, arg, _ := strings.Cut(format, "=")
+
+	switch name {
+	case "json":
+		return printJSON, nil
+	case "yaml":
+		return printYAML, nil
+	case "table":
+		return tablePrinter(false), nil
+	case "wide":
+		return tablePrinter(true), nil
+	case "raw":
+		return printRaw, nil
+	case "jsonpath":
+		return jsonPathPrinter(arg)
+	case "go-template":
+		tmpl, err := template.New("output").Option("missingkey=zero").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		return func(w io.Writer, _ any, raw []byte) error {
+			data, err := decodeGeneric(raw)
+			if err != nil {
+				return err
+			}
+			return tmpl.Execute(w, data)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q: must be json, yaml, table, wide, raw, jsonpath=<expr> or go-template=<template>", format)
+	}
+}
+
+func WriteResponseToRoot(ctx context.Context, cmd *cli.Command, res *http.Response) error {
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res, body, nil)
+}
+
+// WriteTypedResponseToRoot writes the body of a response that has already
+// been read, such as one returned by a ClientWithResponses method. v is the
+// decoded body, if there is one, which picks the columns of table output.
+func WriteTypedResponseToRoot(ctx context.Context, cmd *cli.Command, res *http.Response, body []byte, v any) error {
+	w := cmd.Root().Writer
+
+	// Error and non-JSON bodies, such as attachment contents, are written
+	// as they are.
+	if res.StatusCode >= http.StatusBadRequest || !json.Valid(body) {
+		w.Write(body)
+		if len(body) > 0 && body[len(body)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+		if res.StatusCode >= http.StatusBadRequest {
+			return client.NewAPIError(res, body)
+		}
+		return nil
+	}
+
+	p, err := outputPrinter(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	return p(w, v, body)
+}
+
+func WriteValueToRoot(ctx context.Context, cmd *cli.Command, v any) error {
+	p, err := outputPrinter(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return p(cmd.Root().Writer, v, raw)
+}
+
+func printJSON(w io.Writer, _ any, raw []byte) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return err
+	}
+
+	out.WriteTo(w)
+	fmt.Fprintln(w)
+	return nil
+}
+
+func printYAML(w io.Writer, _ any, raw []byte) error {
+	data, err := decodeGeneric(raw)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// printRaw writes strings without quotes and anything else as compact
+// JSON, which suits piping into other tools.
+func printRaw(w io.Writer, _ any, raw []byte) error {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		fmt.Fprintln(w, s)
+		return nil
+	}
+
+	w.Write(raw)
+	fmt.Fprintln(w)
+	return nil
+}
+
+// decodeGeneric decodes JSON into maps, slices and scalars, keeping
+// numbers as they were written.
+func decodeGeneric(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return normalizeNumbers(data), nil
+}
+
+// normalizeNumbers replaces json.Numbers with int64s or float64s, so that
+// they are printed as numbers by every format.
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+	}
+
+	return v
+}
+
+// column is a table column, showing one value of each row.
+type column struct {
+	header string
+	// wide columns are only shown with -o wide.
+	wide  bool
+	value func(row map[string]any) any
+}
+
+// field returns a column showing the row's key.
+func field(header, key string) column {
+	return column{header: header, value: func(row map[string]any) any { return row[key] }}
+}
+
+// wideField returns a column showing the row's key with -o wide.
+func wideField(header, key string) column {
+	c := field(header, key)
+	c.wide = true
+	return c
+}
+
+var scriptColumns = []column{
+	field("ID", "id"),
+	field("TITLE", "title"),
+	field("STATUS", "status"),
+	field("VERSION", "version_number"),
+	field("INTERPRETER", "interpreter"),
+	wideField("ACCESS GROUP", "access_group"),
+	wideField("TIME LIMIT", "time_limit"),
+	wideField("USERNAME", "username"),
+	{header: "ATTACHMENTS", wide: true, value: func(row map[string]any) any {
+		attachments, _ := row["attachments"].([]any)
+		return len(attachments)
+	}},
+}
+
+var attachmentColumns = []column{
+	field("ID", "id"),
+	field("FILENAME", "filename"),
+}
+
+var activityColumns = []column{
+	field("ID", "id"),
+	field("COMPUTER", "computer_id"),
+	field("STATUS", "activity_status"),
+	field("EXIT CODE", "result_code"),
+	{header: "OUTPUT", value: func(row map[string]any) any {
+		// Keep each activity on one line; -o json shows the output as is.
+		text, _ := row["result_text"].(string)
+		return strings.Join(strings.Fields(text), " ")
+	}},
+	wideField("SUMMARY", "summary"),
+	wideField("COMPLETED", "completion_time"),
+}
+
+var versionColumns = []column{
+	field("VERSION", "version_number"),
+	field("TITLE", "title"),
+	{header: "EDITED BY", value: func(row map[string]any) any {
+		editor, _ := row["last_edited_by"].(map[string]any)
+		return editor["name"]
+	}},
+	field("EDITED AT", "last_edited_at"),
+	wideField("INTERPRETER", "interpreter"),
+	wideField("TIME LIMIT", "time_limit"),
+	wideField("USERNAME", "username"),
+	wideField("ACCESS GROUP", "access_group"),
+}
+
+var migrationColumns = []column{
+	field("V1 ID", "v1_id"),
+	field("V2 ID", "v2_id"),
+	field("TITLE", "title"),
+	{header: "ATTACHMENTS", value: func(row map[string]any) any {
+		attachments, _ := row["attachments"].([]any)
+		return len(attachments)
+	}},
+	field("ARCHIVED", "archived"),
+	field("ERROR", "error"),
+}
+
+var exportColumns = []column{
+	field("ID", "id"),
+	field("TITLE", "title"),
+	field("TYPE", "script_type"),
+	field("STATUS", "status"),
+	{header: "ATTACHMENTS", value: func(row map[string]any) any {
+		attachments, _ := row["attachments"].([]any)
+		return len(attachments)
+	}},
+	{header: "MISSING", value: func(row map[string]any) any {
+		missing, _ := row["missing_attachments"].([]any)
+		return len(missing)
+	}},
+}
+
+var importColumns = []column{
+	field("SOURCE ID", "source_id"),
+	field("ID", "id"),
+	field("TITLE", "title"),
+	field("OUTCOME", "outcome"),
+	field("RESUMED", "resumed"),
+}
+
+var scriptProfileColumns = []column{
+	field("ID", "id"),
+	field("TITLE", "title"),
+	field("SCRIPT", "script_id"),
+	{header: "TRIGGER", value: func(row map[string]any) any {
+		trigger, _ := row["trigger"].(map[string]any)
+		switch trigger["trigger_type"] {
+		case string(client.TriggerEvent):
+			return fmt.Sprintf("event %v", trigger["event_type"])
+		case string(client.TriggerRecurring):
+			return fmt.Sprintf("recurring %v", trigger["interval"])
+		case string(client.TriggerOneTime):
+			return fmt.Sprintf("one-time %v", trigger["timestamp"])
+		}
+		return trigger["trigger_type"]
+	}},
+	{header: "TARGETS", value: func(row map[string]any) any {
+		if all, _ := row["all_computers"].(bool); all {
+			return "all computers"
+		}
+		tags, _ := row["tags"].([]any)
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, fmt.Sprint(tag))
+		}
+		return strings.Join(names, ",")
+	}},
+	{header: "NEXT RUN", wide: true, value: func(row map[string]any) any {
+		trigger, _ := row["trigger"].(map[string]any)
+		return trigger["next_run"]
+	}},
+	wideField("USERNAME", "username"),
+	wideField("TIME LIMIT", "time_limit"),
+}
+
+var contextColumns = []column{
+	{header: "CURRENT", value: func(row map[string]any) any {
+		if current, _ := row["current"].(bool); current {
+			return "*"
+		}
+		return ""
+	}},
+	field("NAME", "name"),
+	field("BASE URL", "base_url"),
+	field("AUTH METHOD", "auth_method"),
+	field("ACCOUNT", "account"),
+	wideField("CA CERT", "ca_cert"),
+	wideField("OUTPUT", "output"),
+}
+
+// tableLayout returns the rows and columns used to show v as a table. The
+// rows are taken from data, which is v decoded by decodeGeneric.
+func tableLayout(v any, data any) ([]any, []column) {
+	rows, ok := data.([]any)
+	if !ok {
+		rows = []any{data}
+	}
+
+	switch v := v.(type) {
+	case client.Script, []client.Script, *client.ScriptResult:
+		return rows, scriptColumns
+	case client.ScriptAttachment, []client.ScriptAttachment:
+		return rows, attachmentColumns
+	case *client.ScriptVersion, []client.ScriptVersion:
+		return rows, versionColumns
+	case []bundle.Script:
+		return rows, exportColumns
+	case []bundle.Result:
+		return rows, importColumns
+	case []scriptMigrationResult:
+		return rows, migrationColumns
+	case *client.ScriptProfileDetails, []client.ScriptProfileDetails:
+		return rows, scriptProfileColumns
+	case *client.Activity, []client.Activity:
+		return rows, activityColumns
+	case []contextSummary:
+		return rows, contextColumns
+	case accountList:
+		current := v.CurrentAccount
+		m, _ := data.(map[string]any)
+		rows, _ := m["accounts"].([]any)
+		return rows, []column{
+			{header: "CURRENT", value: func(row map[string]any) any {
+				if row["name"] == current {
+					return "*"
+				}
+				return ""
+			}},
+			field("NAME", "name"),
+			field("TITLE", "title"),
+			field("DEFAULT", "default"),
+			wideField("SUBDOMAIN", "subdomain"),
+			wideField("CLASSIC DASHBOARD URL", "classic_dashboard_url"),
+		}
+	}
+
+	return rows, genericColumns(rows)
+}
+
+// genericColumns returns a column for every key of the rows, for values
+// without columns of their own.
+func genericColumns(rows []any) []column {
+	var keys []string
+	for _, row := range rows {
+		if m, ok := row.(map[string]any); ok {
+			for k := range m {
+				if !slices.Contains(keys, k) {
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+	slices.Sort(keys)
+
+	columns := make([]column, 0, len(keys))
+	for _, k := range keys {
+		columns = append(columns, field(strings.ToUpper(strings.ReplaceAll(k, "_", " ")), k))
+	}
+
+	return columns
+}
+
+func tablePrinter(wide bool) printer {
+	return func(w io.Writer, v any, raw []byte) error {
+		data, err := decodeGeneric(raw)
+		if err != nil {
+			return err
+		}
+
+		rows, columns := tableLayout(v, data)
+		if !wide {
+			columns = slices.DeleteFunc(slices.Clone(columns), func(c column) bool { return c.wide })
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+
+		if len(columns) == 0 {
+			// Rows of scalars, such as attachment filenames.
+			for _, row := range rows {
+				fmt.Fprintln(tw, formatCell(row))
+			}
+			return tw.Flush()
+		}
+
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = c.header
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+		for _, row := range rows {
+			m, _ := row.(map[string]any)
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = formatCell(c.value(m))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+
+		return tw.Flush()
+	}
+}
+
+// formatCell formats a value for a table cell. Lists of scalars are joined
+// with commas, and objects are shown as compact JSON.
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		cells := make([]string, len(v))
+		for i, e := range v {
+			cells[i] = formatCell(e)
+		}
+		return strings.Join(cells, ",")
+	case map[string]any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var jsonPathSegment = regexp.MustCompile(`^(?:\.([^.\[]+)|\[(\*|-?\d+)\])`)
+
+// jsonPathPrinter returns a printer for a JSONPath expression such as
+// {.attachments[*].filename}. Fields, indexes and [*] are supported, and
+// the results are separated by spaces.
+func jsonPathPrinter(expr string) (printer, error) {
+	path := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(expr), "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+	if path == "" {
+		return nil, fmt.Errorf("jsonpath expression must not be empty")
+	}
+
+	var steps []string
+	for rest := path; rest != ""; {
+		if rest == "." {
+			break
+		}
+
+		m := jsonPathSegment.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid jsonpath expression %q at %q", expr, rest)
+		}
+
+		if m[1] != "" {
+			steps = append(steps, "."+m[1])
+		} else {
+			steps = append(steps, "["+m[2]+"]")
+		}
+		rest = rest[len(m[0]):]
+	}
+
+	return func(w io.Writer, _ any, raw []byte) error {
+		data, err := decodeGeneric(raw)
+		if err != nil {
+			return err
+		}
+
+		results := []any{data}
+		for _, step := range steps {
+			results = applyJSONPathStep(results, step)
+		}
+
+		cells := make([]string, len(results))
+		for i, r := range results {
+			cells[i] = formatCell(r)
+		}
+		fmt.Fprintln(w, strings.Join(cells, " "))
+		return nil
+	}, nil
+}
+
+func applyJSONPathStep(values []any, step string) []any {
+	var out []any
+
+	for _, v := range values {
+		switch {
+		case strings.HasPrefix(step, "."):
+			if m, ok := v.(map[string]any); ok {
+				if e, ok := m[step[1:]]; ok {
+					out = append(out, e)
+				}
+			}
+		case step == "[*]":
+			switch v := v.(type) {
+			case []any:
+				out = append(out, v...)
+			case map[string]any:
+				keys := make([]string, 0, len(v))
+				for k := range v {
+					keys = append(keys, k)
+				}
+				slices.Sort(keys)
+				for _, k := range keys {
+					out = append(out, v[k])
+				}
+			}
+		default:
+			list, ok := v.([]any)
+			if !ok {
+				continue
+			}
+			i, _ := strconv.Atoi(step[1 : len(step)-1])
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				out = append(out, list[i])
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+func TestPrinters(t *testing.T) {
+	attachments := []client.ScriptAttachment{{Id: 1, Filename: "a.txt"}, {Id: 22, Filename: "b.conf"}}
+	contexts := []contextSummary{
+		{Name: "prod", Current: true, BaseURL: "https://prod.example.com", Output: "table"},
+		{Name: "dev", BaseURL: "http://localhost:8080"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		v      any
+		want   string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			v:      attachments[0],
+			want:   "{\n  \"filename\": \"a.txt\",\n  \"id\": 1\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			v:      attachments,
+			want:   "- filename: a.txt\n  id: 1\n- filename: b.conf\n  id: 22\n",
+		},
+		{
+			name:   "table",
+			format: "table",
+			v:      attachments,
+			want:   "ID   FILENAME\n1    a.txt\n22   b.conf\n",
+		},
+		{
+			name:   "table hides wide columns",
+			format: "table",
+			v:      contexts,
+			want: "CURRENT   NAME   BASE URL                   AUTH METHOD   ACCOUNT\n" +
+				"*         prod   https://prod.example.com                 \n" +
+				"          dev    http://localhost:8080                    \n",
+		},
+		{
+			name:   "wide",
+			format: "wide",
+			v:      contexts,
+			want: "CURRENT   NAME   BASE URL                   AUTH METHOD   ACCOUNT   CA CERT   OUTPUT\n" +
+				"*         prod   https://prod.example.com                                     table\n" +
+				"          dev    http://localhost:8080                                        \n",
+		},
+		{
+			name:   "table of scalars",
+			format: "table",
+			v:      []string{"a.txt", "b.conf"},
+			want:   "a.txt\nb.conf\n",
+		},
+		{
+			name:   "table without columns of its own",
+			format: "table",
+			v:      map[string]any{"count": 2, "next_page": nil},
+			want:   "COUNT   NEXT PAGE\n2       \n",
+		},
+		{
+			name:   "raw string",
+			format: "raw",
+			v:      "#!/bin/sh\necho hi",
+			want:   "#!/bin/sh\necho hi\n",
+		},
+		{
+			name:   "raw object",
+			format: "raw",
+			v:      attachments[0],
+			want:   "{\"filename\":\"a.txt\",\"id\":1}\n",
+		},
+		{
+			name:   "jsonpath",
+			format: "jsonpath={.filename}",
+			v:      attachments[0],
+			want:   "a.txt\n",
+		},
+		{
+			name:   "go-template",
+			format: "go-template={{range .}}{{.id}}={{.filename}} {{end}}",
+			v:      attachments,
+			want:   "1=a.txt 22=b.conf ",
+		},
+		{
+			name:   "go-template missing key",
+			format: "go-template={{.missing}}",
+			v:      attachments[0],
+			want:   "<no value>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseOutput(tt.format)
+			if err != nil {
+				t.Fatalf("parseOutput(%q) failed: %v", tt.format, err)
+			}
+
+			raw, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := p(&out, tt.v, raw); err != nil {
+				t.Fatalf("printer failed: %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseOutputErrors(t *testing.T) {
+	for _, format := range []string{"xml", "jsonpath=", "jsonpath={.a[}", "go-template={{.a"} {
+		if _, err := parseOutput(format); err == nil {
+			t.Errorf("expected an error for %q", format)
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	raw := []byte(`{
+		"id": 21433,
+		"title": "backup",
+		"attachments": [{"id": 7, "filename": "a.txt"}, {"id": 8, "filename": "b.conf"}],
+		"tags": {"env": "prod", "team": "ops"},
+		"empty": []
+	}`)
+
+	tests := map[string]string{
+		"{.id}":                       "21433",
+		"{.title}":                    "backup",
+		"$.title":                     "backup",
+		".title":                      "backup",
+		"{.missing}":                  "",
+		"{.missing.deeper}":           "",
+		"{.title.deeper}":             "",
+		"{.attachments[0].filename}":  "a.txt",
+		"{.attachments[-1].filename}": "b.conf",
+		"{.attachments[2].filename}":  "",
+		"{.attachments[-3].filename}": "",
+		"{.title[0]}":                 "",
+		"{.attachments[*].id}":        "7 8",
+		"{.attachments[*].missing}":   "",
+		"{.empty[*]}":                 "",
+		"{.tags[*]}":                  "prod ops",
+		"{.tags}":                     `{"env":"prod","team":"ops"}`,
+		"{.attachments[*]}":           `{"filename":"a.txt","id":7} {"filename":"b.conf","id":8}`,
+		"{.}":                         `{"attachments":[{"filename":"a.txt","id":7},{"filename":"b.conf","id":8}],"empty":[],"id":21433,"tags":{"env":"prod","team":"ops"},"title":"backup"}`,
+	}
+
+	for expr, want := range tests {
+		p, err := jsonPathPrinter(expr)
+		if err != nil {
+			t.Errorf("jsonPathPrinter(%q) failed: %v", expr, err)
+			continue
+		}
+
+		var out bytes.Buffer
+		if err := p(&out, nil, raw); err != nil {
+			t.Errorf("printing %q failed: %v", expr, err)
+			continue
+		}
+		if got := out.String(); got != want+"\n" {
+			t.Errorf("%s: expected %q, got %q", expr, want+"\n", got)
+		}
+	}
+}
+
+func TestWriteTypedResponseToRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json is formatted",
+			status: http.StatusOK,
+			body:   `{"id":1}`,
+			want:   "{\n  \"id\": 1\n}\n",
+		},
+		{
+			name:   "non-json is written as it is",
+			status: http.StatusOK,
+			body:   "dest=/backups",
+			want:   "dest=/backups\n",
+		},
+		{
+			name:    "error body is written as it is",
+			status:  http.StatusNotFound,
+			body:    `{"error":"not-found","message":"Unknown script"}`,
+			want:    `{"error":"not-found","message":"Unknown script"}` + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "error body keeps its newline",
+			status:  http.StatusInternalServerError,
+			body:    "Internal Server Error\n",
+			want:    "Internal Server Error\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &cli.Command{Writer: &out}
+			// The printer must not be used for error bodies, so an invalid
+			// output format is only a problem for successful responses.
+			ctx := context.WithValue(context.Background(), outputKey, "json")
+			if tt.wantErr {
+				ctx = context.WithValue(context.Background(), outputKey, "xml")
+			}
+
+			err := WriteTypedResponseToRoot(ctx, cmd, &http.Response{StatusCode: tt.status}, []byte(tt.body), nil)
+
+			var apiErr *client.APIError
+			if tt.wantErr != errors.As(err, &apiErr) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.wantErr && apiErr.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -3,11 +3,18 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"net/url"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jansdhillon/landscape-go-api-client/client"
 	"github.com/urfave/cli/v3"
@@ -18,8 +25,26 @@ const (
 	fileFlag               = "file"
 	scriptTypeFlag         = "script-type"
 	titleFlag              = "title"
+	interpreterFlag        = "interpreter"
+	timeLimitFlag          = "time-limit"
+	usernameFlag           = "username"
+	accessGroupFlag        = "access-group"
 	scriptIDFlag           = "script-id"
 	scriptAttachmentIDFlag = "script-attachment-id"
+	yesFlag                = "yes"
+	codeFileFlag           = "code-file"
+	attachFlag             = "attach"
+	searchFlag             = "search"
+	statusFlag             = "status"
+	createdByFlag          = "created-by"
+	limitFlag              = "limit"
+	offsetFlag             = "offset"
+	forceFlag              = "force"
+	destFlag               = "dest"
+	dryRunFlag             = "dry-run"
+	queryFlag              = "query"
+	waitFlag               = "wait"
+	pollIntervalFlag       = "poll-interval"
 )
 
 var scriptCmd = &cli.Command{
@@ -36,9 +61,13 @@ var scriptCmd = &cli.Command{
 					Required: true,
 				},
 				&cli.StringFlag{
-					Name:     codeFlag,
-					Aliases:  []string{"c"},
-					Required: true,
+					Name:    codeFlag,
+					Aliases: []string{"c"},
+					Usage:   "The script's code. Either this or -code-file must be provided.",
+				},
+				&cli.StringFlag{
+					Name:  codeFileFlag,
+					Usage: "A file to read the script's code from, or - to read it from stdin.",
 				},
 				&cli.StringFlag{
 					Name:     scriptTypeFlag,
@@ -46,6 +75,26 @@ var scriptCmd = &cli.Command{
 					Required: false,
 					Value:    "V1",
 				},
+				&cli.StringFlag{
+					Name:  interpreterFlag,
+					Usage: "The interpreter to run the script with, such as /bin/bash. Must be provided if the code has no shebang line.",
+				},
+				&cli.IntFlag{
+					Name:  timeLimitFlag,
+					Usage: "The execution time limit for the script in seconds.",
+				},
+				&cli.StringFlag{
+					Name:  usernameFlag,
+					Usage: "The user to run the script as.",
+				},
+				&cli.StringFlag{
+					Name:  accessGroupFlag,
+					Usage: "The access group that can view or execute the script.",
+				},
+				&cli.StringSliceFlag{
+					Name:  attachFlag,
+					Usage: "A file to attach to the script. Can be repeated.",
+				},
 			},
 			Action: createScriptAction,
 		},
@@ -55,24 +104,170 @@ var scriptCmd = &cli.Command{
 			ArgsUsage: "[script-id]",
 			Flags: []cli.Flag{
 				&cli.StringFlag{
-					Name:     titleFlag,
-					Aliases:  []string{"t"},
-					Required: false,
+					Name:    titleFlag,
+					Aliases: []string{"t"},
+					Usage:   "The script's new title.",
 				},
 				&cli.StringFlag{
-					Name:     codeFlag,
-					Aliases:  []string{"c"},
-					Required: true,
+					Name:    codeFlag,
+					Aliases: []string{"c"},
+					Usage:   "The script's new code.",
+				},
+				&cli.StringFlag{
+					Name:  codeFileFlag,
+					Usage: "A file to read the script's new code from, or - to read it from stdin.",
+				},
+				&cli.StringFlag{
+					Name:  interpreterFlag,
+					Usage: "The interpreter to run the script with, such as /bin/bash. Must be provided if the new code has no shebang line. Without new code, the current code is kept with this interpreter.",
+				},
+				&cli.IntFlag{
+					Name:  timeLimitFlag,
+					Usage: "The script's new execution time limit in seconds.",
+				},
+				&cli.StringFlag{
+					Name:  usernameFlag,
+					Usage: "The user to run the script as.",
+				},
+				&cli.StringFlag{
+					Name:  accessGroupFlag,
+					Usage: "The access group that can view or execute the script.",
+				},
+				&cli.StringSliceFlag{
+					Name:  attachFlag,
+					Usage: "A file to attach to the script. Can be repeated.",
+				},
+				&cli.BoolFlag{
+					Name:  dryRunFlag,
+					Usage: "Show the differences between the current and the edited script without editing it.",
+				},
+			},
+			Action:        editScriptAction,
+			ShellComplete: completeScriptID,
+		},
+		{
+			Name:  "list",
+			Usage: "List scripts, optionally filtered.",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    searchFlag,
+					Aliases: []string{"q"},
+					Usage:   "Only list scripts whose title contains this text.",
+				},
+				&cli.StringFlag{
+					Name:  scriptTypeFlag,
+					Usage: "Only list V1 or V2 scripts.",
+				},
+				&cli.StringFlag{
+					Name:  statusFlag,
+					Usage: "Only list V2 scripts with this status: ACTIVE, ARCHIVED or REDACTED.",
+				},
+				&cli.StringFlag{
+					Name:  createdByFlag,
+					Usage: "Only list scripts created by the user with this name or email.",
+				},
+				&cli.StringFlag{
+					Name:  accessGroupFlag,
+					Usage: "Only list scripts in this access group.",
+				},
+				&cli.IntFlag{
+					Name:  limitFlag,
+					Usage: "The largest number of scripts to list, or with -all, to fetch per request.",
+				},
+				&cli.IntFlag{
+					Name:  offsetFlag,
+					Usage: "The number of matching scripts to skip.",
+				},
+				&cli.BoolFlag{
+					Name:  allFlag,
+					Usage: "Fetch every page of matching scripts.",
 				},
 			},
-			Action: editScriptAction,
+			Action: listScriptsAction,
+		},
+		{
+			Name:          "get",
+			Usage:         "Get an existing script.",
+			ArgsUsage:     "[script-id]",
+			Action:        getScriptAction,
+			ShellComplete: completeScriptID,
 		},
 		{
-			Name:      "get",
-			Usage:     "Get an existing script.",
+			Name:      "run",
+			Usage:     "Run a script on the computers matching a query.",
 			ArgsUsage: "[script-id]",
-			Action:    getScriptAction,
+			Description: `The script runs with the attachments it has. Files given with -attach
+are attached to the script for this run only: they are uploaded before the
+run and removed once it has finished, so they need -wait, and they can't
+replace attachments the script already has.`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     queryFlag,
+					Aliases:  []string{"q"},
+					Usage:    "The computers to run the script on, such as tag:servers or id:12.",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    usernameFlag,
+					Aliases: []string{"user"},
+					Usage:   "The user to run the script as, instead of the script's own.",
+				},
+				&cli.IntFlag{
+					Name:  timeLimitFlag,
+					Usage: "The execution time limit in seconds, instead of the script's own.",
+				},
+				&cli.StringSliceFlag{
+					Name:    attachFlag,
+					Aliases: []string{"attachments"},
+					Usage:   "A local file to attach to the script for this run, removing it once the run has finished. Needs -wait. Can be repeated.",
+				},
+				&cli.BoolFlag{
+					Name:  waitFlag,
+					Usage: "Wait for the script to finish on every computer and output the results, failing if it failed on any of them.",
+				},
+				&cli.DurationFlag{
+					Name:  pollIntervalFlag,
+					Usage: "How often to check whether the script has finished with -wait.",
+					Value: 5 * time.Second,
+				},
+			},
+			Action:        runScriptAction,
+			ShellComplete: completeScriptID,
 		},
+		{
+			Name:      "archive",
+			Usage:     "Archive one or more V2 scripts, so that they can no longer be run or edited.",
+			ArgsUsage: "[script-id...]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    yesFlag,
+					Aliases: []string{"y"},
+					Usage:   "Don't ask for confirmation.",
+				},
+			},
+			Action:        archiveScriptsAction,
+			ShellComplete: completeScriptIDs,
+		},
+		{
+			Name:      "redact",
+			Usage:     "Permanently remove the code and attachments of one or more V2 scripts. This can't be undone.",
+			ArgsUsage: "[script-id...]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    yesFlag,
+					Aliases: []string{"y"},
+					Usage:   "Don't ask for confirmation.",
+				},
+			},
+			Action:        redactScriptsAction,
+			ShellComplete: completeScriptIDs,
+		},
+		applyScriptsCmd,
+		diffScriptsCmd,
+		migrateScriptsCmd,
+		exportScriptsCmd,
+		importScriptsCmd,
+		scriptVersionCmd,
 		{
 			Name:  "attachment",
 			Usage: "Create or manage script attachments.",
@@ -82,10 +277,13 @@ var scriptCmd = &cli.Command{
 					Usage: "Create a script attachment.",
 					Flags: []cli.Flag{
 						&cli.StringFlag{
-							Name:     fileFlag,
-							Aliases:  []string{"f"},
-							Usage:    "The file you wish to use as an attachment. The format for this parameter is: <filename>$$<base64 encoded file contents>.",
-							Required: true,
+							Name:    fileFlag,
+							Aliases: []string{"f"},
+							Usage:   "The file you wish to use as an attachment. The format for this parameter is: <filename>$$<base64 encoded file contents>. Prefer -attach for local files.",
+						},
+						&cli.StringSliceFlag{
+							Name:  attachFlag,
+							Usage: "A local file to attach to the script. Can be repeated.",
 						},
 						&cli.Int64Flag{
 							Name:     scriptIDFlag,
@@ -94,12 +292,14 @@ var scriptCmd = &cli.Command{
 							Required: true,
 						},
 					},
-					Action: createScriptAttachmentAction,
+					Action:        createScriptAttachmentAction,
+					ShellComplete: completeAttachmentFlags,
 				},
 				{
-					Name:   "get",
-					Usage:  "Get a script attachment by the script ID and the attachment ID.",
-					Action: getScriptAttachmentAction,
+					Name:          "get",
+					Usage:         "Get a script attachment by the script ID and the attachment ID.",
+					Action:        getScriptAttachmentAction,
+					ShellComplete: completeAttachmentFlags,
 					Flags: []cli.Flag{
 						&cli.Int64Flag{
 							Name:     scriptIDFlag,
@@ -113,7 +313,37 @@ var scriptCmd = &cli.Command{
 							Usage:    "The ID of the script attachment to get.",
 							Required: true,
 						},
+						&cli.StringFlag{
+							Name:    destFlag,
+							Aliases: []string{"d"},
+							Usage:   "Save the attachment to this file, or into this directory with the attachment's filename, instead of writing it to stdout.",
+						},
+						&cli.BoolFlag{
+							Name:  forceFlag,
+							Usage: "Overwrite the file if it already exists.",
+						},
+					},
+				},
+				{
+					Name:          "list",
+					Usage:         "List the attachments of a script.",
+					ArgsUsage:     "[script-id]",
+					Action:        listScriptAttachmentsAction,
+					ShellComplete: completeScriptID,
+				},
+				{
+					Name:      "remove",
+					Usage:     "Remove one or more attachments from a script by filename.",
+					ArgsUsage: "[script-id] [filename...]",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    yesFlag,
+							Aliases: []string{"y"},
+							Usage:   "Don't ask for confirmation.",
+						},
 					},
+					Action:        removeScriptAttachmentsAction,
+					ShellComplete: completeAttachmentFilenames,
 				},
 			},
 		},
@@ -126,24 +356,47 @@ func createScriptAction(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("api client not initialized")
 	}
 
-	title := cmd.String(titleFlag)
-	code := cmd.String(codeFlag)
-	scriptType := cmd.String(scriptTypeFlag)
+	code, ok, err := scriptCode(cmd)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("either -%s or -%s must be provided", codeFlag, codeFileFlag)
+	}
 
-	enc := base64.StdEncoding.EncodeToString([]byte(code))
+	interpreter, err := scriptInterpreter(cmd, code)
+	if err != nil {
+		return err
+	}
 
-	params := client.LegacyActionParams("CreateScript")
-	edit := client.EncodeQueryRequestEditor(url.Values{
-		"title":       []string{title},
-		"code":        []string{enc},
-		"script_type": []string{scriptType},
-	})
+	attachments, err := readAttachments(cmd.StringSlice(attachFlag))
+	if err != nil {
+		return err
+	}
 
-	res, err := api.InvokeLegacyAction(ctx, params, edit)
+	script, err := api.Scripts().Create(ctx, client.CreateScriptParams{
+		Title:       cmd.String(titleFlag),
+		Code:        code,
+		Interpreter: interpreter,
+		TimeLimit:   cmd.Int(timeLimitFlag),
+		Username:    cmd.String(usernameFlag),
+		AccessGroup: cmd.String(accessGroupFlag),
+		ScriptType:  client.ScriptType(cmd.String(scriptTypeFlag)),
+	})
 	if err != nil {
 		return err
 	}
-	return WriteResponseToRoot(ctx, cmd, res)
+
+	if len(attachments) > 0 {
+		id := script.GetID()
+		if script, err = uploadAttachments(ctx, api, id, attachments); err != nil {
+			// The script exists now, so say which one it is rather than
+			// leave it to be created a second time.
+			return fmt.Errorf("script %d was created, but not with all of its attachments: %w", id, err)
+		}
+	}
+
+	return WriteValueToRoot(ctx, cmd, script)
 }
 
 func editScriptAction(ctx context.Context, cmd *cli.Command) error {
@@ -152,34 +405,238 @@ func editScriptAction(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("api not initialized")
 	}
 
-	noArgs := cmd.Args().Len() == 0
-	scriptIDStr := cmd.Args().First()
-	if noArgs || scriptIDStr == "" {
-		return fmt.Errorf("script ID must be provided as the first argument")
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
 	}
 
-	scriptID, err := strconv.Atoi(scriptIDStr)
+	params, err := editScriptParams(cmd)
 	if err != nil {
-		return fmt.Errorf("couldn't convert script ID to string: %s", err)
+		return err
 	}
 
-	title := cmd.String(titleFlag)
-	code := cmd.String(codeFlag)
+	attachments, err := readAttachments(cmd.StringSlice(attachFlag))
+	if err != nil {
+		return err
+	}
 
-	enc := base64.StdEncoding.EncodeToString([]byte(code))
+	if params == (client.EditScriptParams{}) && len(attachments) == 0 {
+		return fmt.Errorf("nothing to edit: provide at least one of -%s, -%s, -%s, -%s, -%s, -%s, -%s or -%s",
+			titleFlag, codeFlag, codeFileFlag, interpreterFlag, timeLimitFlag, usernameFlag, accessGroupFlag, attachFlag)
+	}
 
-	params := client.LegacyActionParams("EditScript")
-	edit := client.EncodeQueryRequestEditor(url.Values{
-		"title":     []string{title},
-		"code":      []string{enc},
-		"script_id": []string{strconv.Itoa(scriptID)},
-	})
+	if cmd.Bool(dryRunFlag) {
+		return editScriptDiff(ctx, cmd, api, scriptID, params, attachments)
+	}
 
-	res, err := api.InvokeLegacyAction(ctx, params, edit)
+	var script client.Script
+	if params != (client.EditScriptParams{}) {
+		if script, err = api.Scripts().Edit(ctx, scriptID, params); err != nil {
+			return err
+		}
+	}
+
+	if len(attachments) > 0 {
+		if script, err = uploadAttachments(ctx, api, scriptID, attachments); err != nil {
+			return err
+		}
+	}
+
+	return WriteValueToRoot(ctx, cmd, script)
+}
+
+// editScriptParams returns the edit with the fields of the flags that were
+// set, leaving the others unchanged.
+func editScriptParams(cmd *cli.Command) (client.EditScriptParams, error) {
+	var params client.EditScriptParams
+
+	code, ok, err := scriptCode(cmd)
+	if err != nil {
+		return params, err
+	}
+	if ok {
+		interpreter, err := scriptInterpreter(cmd, code)
+		if err != nil {
+			return params, err
+		}
+
+		params.Code = &code
+		if interpreter != "" {
+			params.Interpreter = &interpreter
+		}
+	} else if cmd.IsSet(interpreterFlag) {
+		interpreter := cmd.String(interpreterFlag)
+		if interpreter == "" {
+			return params, fmt.Errorf("-%s must not be empty", interpreterFlag)
+		}
+		params.Interpreter = &interpreter
+	}
+
+	if cmd.IsSet(titleFlag) {
+		title := cmd.String(titleFlag)
+		params.Title = &title
+	}
+	if cmd.IsSet(timeLimitFlag) {
+		timeLimit := cmd.Int(timeLimitFlag)
+		params.TimeLimit = &timeLimit
+	}
+	if cmd.IsSet(usernameFlag) {
+		username := cmd.String(usernameFlag)
+		params.Username = &username
+	}
+	if cmd.IsSet(accessGroupFlag) {
+		accessGroup := cmd.String(accessGroupFlag)
+		params.AccessGroup = &accessGroup
+	}
+
+	return params, nil
+}
+
+// editScriptDiff writes the differences between the script with the given
+// ID and the script that editing it with params and attachments would
+// give, without changing it.
+func editScriptDiff(ctx context.Context, cmd *cli.Command, api *client.ClientWithResponses, scriptID int, params client.EditScriptParams, attachments []attachmentFile) error {
+	script, err := api.Scripts().Get(ctx, scriptID)
 	if err != nil {
 		return err
 	}
-	return WriteResponseToRoot(ctx, cmd, res)
+
+	code, err := api.Scripts().GetCode(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+
+	current := scriptDocument{
+		Title:       script.GetTitle(),
+		TimeLimit:   script.GetTimeLimit(),
+		Username:    script.GetUsername(),
+		AccessGroup: script.GetAccessGroup(),
+		Code:        code,
+	}
+	for _, a := range script.GetAttachments() {
+		current.Attachments = append(current.Attachments, a.Filename)
+	}
+
+	proposed := current
+	proposed.Attachments = slices.Clone(current.Attachments)
+	if params.Title != nil {
+		proposed.Title = *params.Title
+	}
+	if params.Code != nil {
+		proposed.Code = *params.Code
+		if params.Interpreter != nil {
+			proposed.Code = client.SetShebang(*params.Code, *params.Interpreter)
+		}
+	} else if params.Interpreter != nil {
+		proposed.Code = client.SetShebang(code, *params.Interpreter)
+	}
+	if params.TimeLimit != nil {
+		proposed.TimeLimit = *params.TimeLimit
+	}
+	if params.Username != nil {
+		proposed.Username = *params.Username
+	}
+	if params.AccessGroup != nil {
+		proposed.AccessGroup = *params.AccessGroup
+	}
+	for _, a := range attachments {
+		proposed.Attachments = append(proposed.Attachments, a.name)
+	}
+
+	name := fmt.Sprintf("script/%d", scriptID)
+	diff := unifiedDiff(name+" (current)", name+" (edited)", current.String(), proposed.String())
+	if diff == "" {
+		diff = "No changes.\n"
+	}
+
+	_, err = io.WriteString(cmd.Root().Writer, diff)
+	return err
+}
+
+// scriptDocument is the text form of a script that edits are diffed in.
+type scriptDocument struct {
+	Title       string
+	TimeLimit   int
+	Username    string
+	AccessGroup string
+	Attachments []string
+	Code        string
+}
+
+func (d scriptDocument) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "title: %s\n", d.Title)
+	fmt.Fprintf(&b, "time limit: %d\n", d.TimeLimit)
+	fmt.Fprintf(&b, "username: %s\n", d.Username)
+	fmt.Fprintf(&b, "access group: %s\n", d.AccessGroup)
+	for _, a := range d.Attachments {
+		fmt.Fprintf(&b, "attachment: %s\n", a)
+	}
+	fmt.Fprintf(&b, "\n%s", d.Code)
+	if !strings.HasSuffix(d.Code, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func listScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	params := client.ListScriptsParams{
+		Search:      cmd.String(searchFlag),
+		ScriptType:  client.ScriptType(strings.ToUpper(cmd.String(scriptTypeFlag))),
+		Status:      client.V2ScriptStatus(strings.ToUpper(cmd.String(statusFlag))),
+		CreatedBy:   cmd.String(createdByFlag),
+		AccessGroup: cmd.String(accessGroupFlag),
+		Limit:       cmd.Int(limitFlag),
+		Offset:      cmd.Int(offsetFlag),
+	}
+
+	switch params.ScriptType {
+	case "", client.ScriptTypeV1, client.ScriptTypeV2:
+	default:
+		return fmt.Errorf("script type must be V1 or V2")
+	}
+	switch params.Status {
+	case "", client.ACTIVE, client.ARCHIVED, client.REDACTED:
+	default:
+		return fmt.Errorf("status must be ACTIVE, ARCHIVED or REDACTED")
+	}
+
+	if !cmd.Bool(allFlag) {
+		page, err := api.Scripts().List(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if err := WriteValueToRoot(ctx, cmd, page.Scripts); err != nil {
+			return err
+		}
+		if page.More {
+			next := params.Offset + len(page.Scripts)
+			total := ""
+			if page.Count > 0 {
+				total = fmt.Sprintf(" of %d", page.Count)
+			}
+			fmt.Fprintf(cmd.Root().ErrWriter, "showing scripts %d to %d%s: use -%s %d for the next page, or -%s for every script\n",
+				params.Offset+1, next, total, offsetFlag, next, allFlag)
+		}
+
+		return nil
+	}
+
+	scripts := []client.Script{}
+	for script, err := range api.Scripts().All(ctx, params) {
+		if err != nil {
+			return err
+		}
+		scripts = append(scripts, script)
+	}
+
+	return WriteValueToRoot(ctx, cmd, scripts)
 }
 
 func getScriptAction(ctx context.Context, cmd *cli.Command) error {
@@ -188,23 +645,369 @@ func getScriptAction(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("api client not initialized")
 	}
 
-	noArgs := cmd.Args().Len() == 0
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	script, err := api.Scripts().Get(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, script)
+}
+
+func runScriptAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Duration(pollIntervalFlag) <= 0 {
+		return fmt.Errorf("-%s must be positive", pollIntervalFlag)
+	}
+
+	attachments, err := readAttachments(cmd.StringSlice(attachFlag))
+	if err != nil {
+		return err
+	}
+	if len(attachments) > 0 {
+		// The computers fetch the attachments while the script runs, so
+		// they can only be removed once it has finished everywhere.
+		if !cmd.Bool(waitFlag) {
+			return fmt.Errorf("-%s needs -%s, so that the attachments can be removed once the script has finished", attachFlag, waitFlag)
+		}
+		if err := attachForRun(ctx, api, scriptID, attachments); err != nil {
+			return err
+		}
+	}
+
+	activity, err := api.Scripts().Execute(ctx, scriptID, client.ExecuteScriptParams{
+		Query:     cmd.String(queryFlag),
+		Username:  cmd.String(usernameFlag),
+		TimeLimit: cmd.Int(timeLimitFlag),
+	})
+	if err != nil {
+		return errors.Join(err, removeRunAttachments(ctx, api, scriptID, attachments))
+	}
+
+	if !cmd.Bool(waitFlag) {
+		return WriteValueToRoot(ctx, cmd, activity)
+	}
+
+	fmt.Fprintf(cmd.Root().ErrWriter, "waiting for activity %d to finish...\n", activity.Id)
+
+	results, err := api.Activities().Wait(ctx, activity.Id, cmd.Duration(pollIntervalFlag))
+	if err != nil {
+		if len(attachments) > 0 {
+			return fmt.Errorf("failed to wait for activity %d, so the attachments added for the run were left on script %d: %w", activity.Id, scriptID, err)
+		}
+		return fmt.Errorf("failed to wait for activity %d: %w", activity.Id, err)
+	}
+
+	if err := removeRunAttachments(ctx, api, scriptID, attachments); err != nil {
+		return err
+	}
+
+	if err := WriteValueToRoot(ctx, cmd, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.ActivityStatus != client.ActivitySucceeded {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("the script failed on %d of %d computers", failed, len(results))
+	}
+
+	return nil
+}
+
+// scriptCode returns the code given with -code or -code-file, reading
+// stdin if the file is -. It reports false if neither was given.
+func scriptCode(cmd *cli.Command) (string, bool, error) {
+	if cmd.IsSet(codeFlag) && cmd.IsSet(codeFileFlag) {
+		return "", false, fmt.Errorf("-%s and -%s can't be used together", codeFlag, codeFileFlag)
+	}
+
+	if cmd.IsSet(codeFlag) {
+		return cmd.String(codeFlag), true, nil
+	}
+
+	path := cmd.String(codeFileFlag)
+	if path == "" {
+		return "", false, nil
+	}
+
+	var code []byte
+	var err error
+	if path == "-" {
+		code, err = io.ReadAll(cmd.Root().Reader)
+	} else {
+		code, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read code: %w", err)
+	}
+
+	return string(code), true, nil
+}
+
+// scriptInterpreter returns the -interpreter flag, checking that it agrees
+// with the code's shebang line. One of them must name the interpreter.
+func scriptInterpreter(cmd *cli.Command, code string) (string, error) {
+	interpreter := cmd.String(interpreterFlag)
+	shebang := client.ShebangInterpreter(code)
+
+	switch {
+	case shebang == "" && interpreter == "":
+		return "", fmt.Errorf("the code has no shebang line, so -%s must be provided", interpreterFlag)
+	case shebang != "" && interpreter != "" && shebang != interpreter:
+		return "", fmt.Errorf("-%s %q doesn't match the code's shebang line %q", interpreterFlag, interpreter, shebang)
+	}
+
+	return interpreter, nil
+}
+
+// attachmentFile is a local file to be attached to a script.
+type attachmentFile struct {
+	path     string
+	name     string
+	contents []byte
+}
+
+// readAttachments reads the files at paths, checking that each is within
+// Landscape's attachment size limit before anything is uploaded.
+func readAttachments(paths []string) ([]attachmentFile, error) {
+	attachments := make([]attachmentFile, 0, len(paths))
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment: %w", err)
+		}
+		if info.Size() > client.MaxAttachmentSize {
+			return nil, fmt.Errorf("%w: %s is %d bytes, the limit is %d bytes", client.ErrAttachmentTooLarge, path, info.Size(), client.MaxAttachmentSize)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment: %w", err)
+		}
+
+		attachments = append(attachments, attachmentFile{
+			path:     path,
+			name:     filepath.Base(path),
+			contents: contents,
+		})
+	}
+
+	return attachments, nil
+}
+
+// uploadAttachments attaches the files to the script and returns the
+// script as it is afterwards.
+func uploadAttachments(ctx context.Context, api *client.ClientWithResponses, scriptID int, attachments []attachmentFile) (client.Script, error) {
+	for _, a := range attachments {
+		if _, err := api.Scripts().AddAttachment(ctx, scriptID, a.name, a.contents); err != nil {
+			return nil, fmt.Errorf("failed to attach %s to script %d: %w", a.path, scriptID, err)
+		}
+	}
+
+	return api.Scripts().Get(ctx, scriptID)
+}
+
+// attachForRun attaches the files to the script for a single run. The run
+// removes them afterwards, so it refuses to touch attachments the script
+// already has, and removes the ones it added if one of them fails.
+func attachForRun(ctx context.Context, api *client.ClientWithResponses, scriptID int, attachments []attachmentFile) error {
+	script, err := api.Scripts().Get(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range attachments {
+		if slices.ContainsFunc(script.GetAttachments(), func(existing client.ScriptAttachment) bool { return existing.Filename == a.name }) {
+			return fmt.Errorf("script %d already has an attachment named %s, which the run would remove afterwards", scriptID, a.name)
+		}
+	}
+
+	for i, a := range attachments {
+		if _, err := api.Scripts().AddAttachment(ctx, scriptID, a.name, a.contents); err != nil {
+			err = fmt.Errorf("failed to attach %s to script %d: %w", a.path, scriptID, err)
+			return errors.Join(err, removeRunAttachments(ctx, api, scriptID, attachments[:i]))
+		}
+	}
+
+	return nil
+}
+
+// removeRunAttachments removes the attachments added for a run, trying
+// every one of them.
+func removeRunAttachments(ctx context.Context, api *client.ClientWithResponses, scriptID int, attachments []attachmentFile) error {
+	var errs []error
+	for _, a := range attachments {
+		if err := api.Scripts().RemoveAttachment(ctx, scriptID, a.name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove attachment %s from script %d: %w", a.name, scriptID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// scriptIDArg parses the script ID given as the command's first argument.
+func scriptIDArg(cmd *cli.Command) (int, error) {
 	scriptIDStr := cmd.Args().First()
-	if noArgs || scriptIDStr == "" {
-		return fmt.Errorf("script ID must be provided as the first argument")
+	if scriptIDStr == "" {
+		return 0, fmt.Errorf("script ID must be provided as the first argument")
 	}
 
 	scriptID, err := strconv.Atoi(scriptIDStr)
 	if err != nil {
-		return fmt.Errorf("couldn't convert script ID to string: %s", err)
+		return 0, fmt.Errorf("couldn't convert script ID to int: %s", err)
+	}
+
+	return scriptID, nil
+}
+
+// scriptIDArgs parses the script IDs given as the command's arguments.
+func scriptIDArgs(cmd *cli.Command) ([]int, error) {
+	if cmd.Args().Len() == 0 {
+		return nil, fmt.Errorf("at least one script ID must be provided as an argument")
 	}
 
-	res, err := api.GetScript(ctx, scriptID)
+	ids := make([]int, 0, cmd.Args().Len())
+	for _, arg := range cmd.Args().Slice() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't convert script ID %q to int: %s", arg, err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func archiveScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	return changeScriptsStatus(ctx, cmd, "archive", "archived", func(script *client.V2Script) error {
+		if script.Status != client.ACTIVE {
+			return fmt.Errorf("script is %s, only active scripts can be archived", script.Status)
+		}
+		return nil
+	}, func(api *client.ClientWithResponses, id int) error {
+		return api.Scripts().Archive(ctx, id)
+	})
+}
+
+func redactScriptsAction(ctx context.Context, cmd *cli.Command) error {
+	return changeScriptsStatus(ctx, cmd, "redact", "redacted", func(script *client.V2Script) error {
+		if script.IsRedactable == nil || !*script.IsRedactable {
+			return fmt.Errorf("script can't be redacted")
+		}
+		return nil
+	}, func(api *client.ClientWithResponses, id int) error {
+		return api.Scripts().Redact(ctx, id)
+	})
+}
+
+// changeScriptsStatus fetches each script given as an argument, checks it
+// with allowed, asks for confirmation and then calls change on it. verb
+// and done describe the change, such as "archive" and "archived". Errors
+// are reported for each script, without stopping at the first one.
+func changeScriptsStatus(ctx context.Context, cmd *cli.Command, verb, done string, allowed func(*client.V2Script) error, change func(*client.ClientWithResponses, int) error) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	ids, err := scriptIDArgs(cmd)
 	if err != nil {
 		return err
 	}
 
-	return WriteResponseToRoot(ctx, cmd, res)
+	in := bufio.NewReader(cmd.Root().Reader)
+	out := cmd.Root().Writer
+
+	failed := 0
+	for _, id := range ids {
+		err := func() error {
+			script, err := api.Scripts().Get(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			v2, ok := script.(*client.V2Script)
+			if !ok {
+				return fmt.Errorf("only V2 scripts can be %s", done)
+			}
+			if err := allowed(v2); err != nil {
+				return err
+			}
+
+			if !cmd.Bool(yesFlag) {
+				describeScriptChange(out, verb, v2)
+
+				ok, err := confirm(in, out, fmt.Sprintf("Do you want to %s script %d?", verb, id))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Fprintf(out, "skipped script %d\n", id)
+					return nil
+				}
+			}
+
+			if err := change(api, id); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s script %d\n", done, id)
+			return nil
+		}()
+		if err != nil {
+			failed++
+			fmt.Fprintf(cmd.Root().ErrWriter, "script %d: failed to %s: %s\n", id, verb, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d scripts", verb, failed, len(ids))
+	}
+
+	return nil
+}
+
+// describeScriptChange shows what archiving or redacting script will
+// affect.
+func describeScriptChange(w io.Writer, verb string, script *client.V2Script) {
+	fmt.Fprintf(w, "Script %d %q, version %d, %s:\n", script.Id, script.Title, script.GetVersion(), script.Status)
+
+	if verb != "redact" {
+		fmt.Fprintln(w, "  It will no longer be possible to run or edit it.")
+		return
+	}
+
+	lines := 0
+	if script.Code != nil {
+		lines = strings.Count(*script.Code, "\n") + 1
+	}
+	fmt.Fprintf(w, "  Its code (%d lines) will be permanently removed.\n", lines)
+
+	for _, attachment := range script.GetAttachments() {
+		fmt.Fprintf(w, "  Attachment %q will be permanently removed.\n", attachment.Filename)
+	}
+	if profiles := script.ScriptProfiles; profiles != nil && len(*profiles) > 0 {
+		fmt.Fprintf(w, "  It is used by %d script profiles.\n", len(*profiles))
+	}
 }
 
 func getScriptAttachmentAction(ctx context.Context, cmd *cli.Command) error {
@@ -216,12 +1019,123 @@ func getScriptAttachmentAction(ctx context.Context, cmd *cli.Command) error {
 	scriptID := cmd.Int64(scriptIDFlag)
 	attachmentID := cmd.Int64(scriptAttachmentIDFlag)
 
-	res, err := api.GetScriptAttachment(ctx, int(scriptID), int(attachmentID))
+	dest := cmd.String(destFlag)
+	if dest == "" {
+		if _, err := api.Scripts().DownloadAttachment(ctx, int(scriptID), int(attachmentID), cmd.Root().Writer); err != nil {
+			return fmt.Errorf("failed to get script attachment: %w", err)
+		}
+		return nil
+	}
+
+	dir, isDir := dest, true
+	if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+		dir, isDir = filepath.Dir(dest), false
+	}
+
+	// The filename is only known once the attachment is downloaded, so it
+	// is saved to a temporary file first.
+	tmp, err := os.CreateTemp(dir, ".landscape-attachment-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	filename, err := api.Scripts().DownloadAttachment(ctx, int(scriptID), int(attachmentID), tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get script attachment: %w", err)
 	}
 
-	return WriteResponseToRoot(ctx, cmd, res)
+	path := dest
+	if isDir {
+		name := filepath.Base(filename)
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return fmt.Errorf("attachment has an invalid filename: %q", filename)
+		}
+		path = filepath.Join(dir, name)
+	}
+
+	if _, err := os.Stat(path); err == nil && !cmd.Bool(forceFlag) {
+		return fmt.Errorf("%s already exists, pass -%s to overwrite it", path, forceFlag)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	fmt.Fprintf(cmd.Root().Writer, "saved %s to %s\n", filename, path)
+	return nil
+}
+
+func listScriptAttachmentsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := api.Scripts().ListAttachments(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, attachments)
+}
+
+func removeScriptAttachmentsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	filenames := cmd.Args().Tail()
+	if len(filenames) == 0 {
+		return fmt.Errorf("at least one attachment filename must be provided after the script ID")
+	}
+
+	in := bufio.NewReader(cmd.Root().Reader)
+	out := cmd.Root().Writer
+
+	failed := 0
+	for _, filename := range filenames {
+		if !cmd.Bool(yesFlag) {
+			ok, err := confirm(in, out, fmt.Sprintf("Do you want to remove %q from script %d?", filename, scriptID))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Fprintf(out, "skipped %s\n", filename)
+				continue
+			}
+		}
+
+		if err := api.Scripts().RemoveAttachment(ctx, scriptID, filename); err != nil {
+			failed++
+			fmt.Fprintf(cmd.Root().ErrWriter, "%s: failed to remove: %s\n", filename, err)
+			continue
+		}
+
+		fmt.Fprintf(out, "removed %s\n", filename)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d of %d attachments", failed, len(filenames))
+	}
+
+	return nil
 }
 
 func createScriptAttachmentAction(ctx context.Context, cmd *cli.Command) error {
@@ -231,18 +1145,47 @@ func createScriptAttachmentAction(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	scriptID := cmd.Int64(scriptIDFlag)
-	file := cmd.String(fileFlag)
 
-	params := client.LegacyActionParams("CreateScriptAttachment")
-	edit := client.EncodeQueryRequestEditor(url.Values{
-		"script_id": []string{fmt.Sprintf("%d", scriptID)},
-		"file":      []string{file},
-	})
+	if paths := cmd.StringSlice(attachFlag); len(paths) > 0 {
+		if cmd.IsSet(fileFlag) {
+			return fmt.Errorf("-%s and -%s can't be used together", fileFlag, attachFlag)
+		}
+
+		attachments, err := readAttachments(paths)
+		if err != nil {
+			return err
+		}
+
+		filenames := make([]string, 0, len(attachments))
+		for _, a := range attachments {
+			filename, err := api.Scripts().AddAttachment(ctx, int(scriptID), a.name, a.contents)
+			if err != nil {
+				return fmt.Errorf("failed to attach %s: %w", a.path, err)
+			}
+			filenames = append(filenames, filename)
+		}
+
+		return WriteValueToRoot(ctx, cmd, filenames)
+	}
+
+	if !cmd.IsSet(fileFlag) {
+		return fmt.Errorf("either -%s or -%s must be provided", fileFlag, attachFlag)
+	}
+
+	filename, enc, found := strings.Cut(cmd.String(fileFlag), "$$")
+	if !found {
+		return fmt.Errorf("file must be in the format <filename>$$<base64 encoded file contents>")
+	}
+
+	contents, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return fmt.Errorf("file contents aren't base64 encoded: %w", err)
+	}
 
-	res, err := api.InvokeLegacyAction(ctx, params, edit)
+	attachment, err := api.Scripts().AddAttachment(ctx, int(scriptID), filename, contents)
 	if err != nil {
 		return err
 	}
 
-	return WriteResponseToRoot(ctx, cmd, res)
+	return WriteValueToRoot(ctx, cmd, attachment)
 }
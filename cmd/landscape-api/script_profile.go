@@ -0,0 +1,396 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	triggerFlag      = "trigger"
+	eventFlag        = "event"
+	intervalFlag     = "interval"
+	startAfterFlag   = "start-after"
+	atFlag           = "at"
+	tagFlag          = "tag"
+	allComputersFlag = "all-computers"
+)
+
+// scriptProfileFlags returns the flags shared by script-profile create and
+// edit. Nothing is required, since edit only changes the flags that are
+// set; create checks its required flags itself.
+func scriptProfileFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    titleFlag,
+			Aliases: []string{"t"},
+			Usage:   "The profile's title.",
+		},
+		&cli.StringFlag{
+			Name:  triggerFlag,
+			Usage: "What runs the script: event, recurring or one-time.",
+		},
+		&cli.StringFlag{
+			Name:  eventFlag,
+			Usage: "The event that runs the script, for event triggers.",
+			Value: strings.ReplaceAll(client.EventPostEnrollment, "_", "-"),
+		},
+		&cli.StringFlag{
+			Name:  intervalFlag,
+			Usage: `The cron expression the script runs on, such as "0 3 * * 1", for recurring triggers.`,
+		},
+		&cli.StringFlag{
+			Name:  startAfterFlag,
+			Usage: "When a recurring trigger starts, in RFC 3339 format.",
+		},
+		&cli.StringFlag{
+			Name:  atFlag,
+			Usage: "When a one-time trigger runs the script, in RFC 3339 format.",
+		},
+		&cli.StringSliceFlag{
+			Name:  tagFlag,
+			Usage: "A tag of the computers to run the script on. Can be repeated.",
+		},
+		&cli.BoolFlag{
+			Name:  allComputersFlag,
+			Usage: "Run the script on every computer, rather than those with the tags.",
+		},
+		&cli.StringFlag{
+			Name:  usernameFlag,
+			Usage: "The user to run the script as.",
+		},
+		&cli.IntFlag{
+			Name:  timeLimitFlag,
+			Usage: "The execution time limit for the script in seconds.",
+		},
+	}
+}
+
+var scriptProfileCmd = &cli.Command{
+	Name:  "script-profile",
+	Usage: "Manage script profiles, which run a script on a schedule or when computers are enrolled.",
+	Commands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List script profiles.",
+			Action: listScriptProfilesAction,
+		},
+		{
+			Name:          "get",
+			Usage:         "Get a script profile by its ID.",
+			ArgsUsage:     "[profile-id]",
+			Action:        getScriptProfileAction,
+			ShellComplete: completeScriptProfileID,
+		},
+		{
+			Name:  "create",
+			Usage: "Create a script profile.",
+			Flags: append(scriptProfileFlags(),
+				&cli.Int64Flag{
+					Name:     scriptIDFlag,
+					Aliases:  []string{"s"},
+					Usage:    "The ID of the script to run.",
+					Required: true,
+				},
+			),
+			Action:        createScriptProfileAction,
+			ShellComplete: completeScriptProfileFlags,
+		},
+		{
+			Name:          "edit",
+			Usage:         "Edit a script profile. Only the flags that are set are changed.",
+			ArgsUsage:     "[profile-id]",
+			Flags:         scriptProfileFlags(),
+			Action:        editScriptProfileAction,
+			ShellComplete: completeScriptProfileID,
+		},
+		{
+			Name:      "archive",
+			Usage:     "Archive script profiles, so that they no longer run their scripts.",
+			ArgsUsage: "[profile-id...]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    yesFlag,
+					Aliases: []string{"y"},
+					Usage:   "Don't ask for confirmation.",
+				},
+			},
+			Action:        archiveScriptProfilesAction,
+			ShellComplete: completeScriptProfileIDs,
+		},
+		{
+			Name:          "activities",
+			Usage:         "List the activities a script profile created each time it ran its script.",
+			ArgsUsage:     "[profile-id]",
+			Action:        listScriptProfileActivitiesAction,
+			ShellComplete: completeScriptProfileID,
+		},
+	},
+}
+
+func listScriptProfilesAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	profiles, err := api.ScriptProfiles().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, profiles)
+}
+
+func getScriptProfileAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	id, err := profileIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	profile, err := api.ScriptProfiles().Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, profile)
+}
+
+func createScriptProfileAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	if !cmd.IsSet(titleFlag) {
+		return fmt.Errorf("-%s must be provided", titleFlag)
+	}
+	if !cmd.IsSet(triggerFlag) {
+		return fmt.Errorf("-%s must be provided", triggerFlag)
+	}
+
+	trigger, err := scriptProfileTrigger(cmd)
+	if err != nil {
+		return err
+	}
+
+	profile, err := api.ScriptProfiles().Create(ctx, client.CreateScriptProfileParams{
+		Title:        cmd.String(titleFlag),
+		ScriptID:     int(cmd.Int64(scriptIDFlag)),
+		Trigger:      trigger,
+		Tags:         cmd.StringSlice(tagFlag),
+		AllComputers: cmd.Bool(allComputersFlag),
+		Username:     cmd.String(usernameFlag),
+		TimeLimit:    cmd.Int(timeLimitFlag),
+	})
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, profile)
+}
+
+func editScriptProfileAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	id, err := profileIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	var params client.EditScriptProfileParams
+
+	if cmd.IsSet(triggerFlag) {
+		trigger, err := scriptProfileTrigger(cmd)
+		if err != nil {
+			return err
+		}
+		params.Trigger = &trigger
+	} else {
+		for _, name := range []string{eventFlag, intervalFlag, startAfterFlag, atFlag} {
+			if cmd.IsSet(name) {
+				return fmt.Errorf("-%s must be provided to change -%s", triggerFlag, name)
+			}
+		}
+	}
+
+	if cmd.IsSet(titleFlag) {
+		title := cmd.String(titleFlag)
+		params.Title = &title
+	}
+	if cmd.IsSet(tagFlag) {
+		tags := cmd.StringSlice(tagFlag)
+		params.Tags = &tags
+	}
+	if cmd.IsSet(allComputersFlag) {
+		all := cmd.Bool(allComputersFlag)
+		params.AllComputers = &all
+	}
+	if cmd.IsSet(usernameFlag) {
+		username := cmd.String(usernameFlag)
+		params.Username = &username
+	}
+	if cmd.IsSet(timeLimitFlag) {
+		timeLimit := cmd.Int(timeLimitFlag)
+		params.TimeLimit = &timeLimit
+	}
+
+	if params == (client.EditScriptProfileParams{}) {
+		return fmt.Errorf("nothing to edit: provide at least one of -%s, -%s, -%s, -%s, -%s or -%s",
+			titleFlag, triggerFlag, tagFlag, allComputersFlag, usernameFlag, timeLimitFlag)
+	}
+
+	profile, err := api.ScriptProfiles().Edit(ctx, id, params)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, profile)
+}
+
+func archiveScriptProfilesAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	if cmd.Args().Len() == 0 {
+		return fmt.Errorf("at least one script profile ID must be provided as an argument")
+	}
+
+	in := bufio.NewReader(cmd.Root().Reader)
+	out := cmd.Root().Writer
+
+	failed := 0
+	for _, arg := range cmd.Args().Slice() {
+		err := func() error {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("couldn't convert script profile ID %q to int: %s", arg, err)
+			}
+
+			profile, err := api.ScriptProfiles().Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			if profile.Archived {
+				return fmt.Errorf("script profile %d is already archived", id)
+			}
+
+			if !cmd.Bool(yesFlag) {
+				fmt.Fprintf(out, "Script profile %d %q runs script %d %s.\n", id, profile.Title, profile.ScriptId, describeTrigger(profile.Trigger))
+
+				ok, err := confirm(in, out, fmt.Sprintf("Do you want to archive script profile %d?", id))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Fprintf(out, "skipped script profile %d\n", id)
+					return nil
+				}
+			}
+
+			if err := api.ScriptProfiles().Archive(ctx, id); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "archived script profile %d\n", id)
+			return nil
+		}()
+		if err != nil {
+			failed++
+			fmt.Fprintf(cmd.Root().ErrWriter, "script profile %s: failed to archive: %s\n", arg, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to archive %d of %d script profiles", failed, cmd.Args().Len())
+	}
+
+	return nil
+}
+
+func listScriptProfileActivitiesAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	id, err := profileIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	activities, err := api.ScriptProfiles().Activities(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, activities)
+}
+
+// scriptProfileTrigger returns the trigger described by the trigger flags.
+// Names are accepted with dashes, such as one-time and post-enrollment.
+func scriptProfileTrigger(cmd *cli.Command) (client.ScriptProfileTrigger, error) {
+	triggerType := client.ScriptProfileTriggerType(strings.ReplaceAll(cmd.String(triggerFlag), "-", "_"))
+
+	trigger := client.ScriptProfileTrigger{TriggerType: triggerType}
+	switch triggerType {
+	case client.TriggerEvent:
+		trigger.EventType = strings.ReplaceAll(cmd.String(eventFlag), "-", "_")
+	case client.TriggerRecurring:
+		trigger.Interval = cmd.String(intervalFlag)
+		trigger.StartAfter = cmd.String(startAfterFlag)
+	case client.TriggerOneTime:
+		trigger.Timestamp = cmd.String(atFlag)
+	default:
+		return trigger, fmt.Errorf("-%s must be event, recurring or one-time, got %q", triggerFlag, cmd.String(triggerFlag))
+	}
+
+	return trigger, trigger.Validate()
+}
+
+// describeTrigger returns when a trigger runs its script, such as "on
+// post_enrollment".
+func describeTrigger(t client.ScriptProfileTrigger) string {
+	switch t.TriggerType {
+	case client.TriggerEvent:
+		return "on " + t.EventType
+	case client.TriggerRecurring:
+		return fmt.Sprintf("on %q", t.Interval)
+	case client.TriggerOneTime:
+		return "at " + t.Timestamp
+	}
+	return string(t.TriggerType)
+}
+
+// profileIDArg parses the script profile ID given as the command's first
+// argument.
+func profileIDArg(cmd *cli.Command) (int, error) {
+	idStr := cmd.Args().First()
+	if idStr == "" {
+		return 0, fmt.Errorf("script profile ID must be provided as the first argument")
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't convert script profile ID to int: %s", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+func TestChangeScriptsStatus(t *testing.T) {
+	scripts := map[string]map[string]any{
+		"1": {"id": 1, "title": "backup", "status": "ACTIVE", "version_number": 2, "code": "#!/bin/sh\ntar c /srv", "is_redactable": true, "attachments": []map[string]any{{"id": 7, "filename": "exclude.txt"}}},
+		"2": {"id": 2, "title": "restore", "status": "ACTIVE", "version_number": 1, "is_redactable": false},
+		"4": {"id": 4, "title": "cleanup", "status": "ACTIVE", "version_number": 1},
+		"5": {"id": 5, "title": "legacy", "status": "V1"},
+	}
+
+	var changed []string
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api/scripts/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/scripts/"), ":")
+		if action != "" {
+			if id == "4" {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]any{"message": "try again"})
+				return
+			}
+			changed = append(changed, action+" "+id)
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(scripts[id])
+			return
+		}
+
+		script, ok := scripts[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"message": "Unknown script"})
+			return
+		}
+		json.NewEncoder(w).Encode(script)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := client.NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		action      cli.ActionFunc
+		args        []string
+		input       string
+		wantChanged []string
+		wantOut     []string
+		wantErrOut  []string
+		wantErr     string
+	}{
+		{
+			name:        "declined",
+			action:      archiveScriptsAction,
+			args:        []string{"1"},
+			input:       "n\n",
+			wantOut:     []string{"It will no longer be possible to run or edit it.", "Do you want to archive script 1? [y/N]: ", "skipped script 1\n"},
+			wantChanged: nil,
+		},
+		{
+			name:        "no answer",
+			action:      archiveScriptsAction,
+			args:        []string{"1"},
+			wantOut:     []string{"skipped script 1\n"},
+			wantChanged: nil,
+		},
+		{
+			name:        "confirmed",
+			action:      archiveScriptsAction,
+			args:        []string{"1"},
+			input:       "y\n",
+			wantOut:     []string{"archived script 1\n"},
+			wantChanged: []string{"archive 1"},
+		},
+		{
+			name:        "yes",
+			action:      archiveScriptsAction,
+			args:        []string{"-yes", "1"},
+			wantOut:     []string{"archived script 1\n"},
+			wantChanged: []string{"archive 1"},
+		},
+		{
+			name:        "redact describes what is removed",
+			action:      redactScriptsAction,
+			args:        []string{"1"},
+			input:       "yes\n",
+			wantOut:     []string{"Its code (2 lines) will be permanently removed.", `Attachment "exclude.txt" will be permanently removed.`, "redacted script 1\n"},
+			wantChanged: []string{"redact 1"},
+		},
+		{
+			name:        "not redactable",
+			action:      redactScriptsAction,
+			args:        []string{"-yes", "2"},
+			wantErrOut:  []string{"script 2: failed to redact: script can't be redacted\n"},
+			wantErr:     "failed to redact 1 of 1 scripts",
+			wantChanged: nil,
+		},
+		{
+			name:        "errors don't stop the other scripts",
+			action:      archiveScriptsAction,
+			args:        []string{"-yes", "3", "4", "5", "1"},
+			wantOut:     []string{"archived script 1\n"},
+			wantErrOut:  []string{"script 3: failed to archive: ", "script 4: failed to archive: ", "script 5: failed to archive: only V2 scripts can be archived\n"},
+			wantErr:     "failed to archive 3 of 4 scripts",
+			wantChanged: []string{"archive 1"},
+		},
+		{
+			name:        "each script is confirmed",
+			action:      archiveScriptsAction,
+			args:        []string{"1", "2"},
+			input:       "n\ny\n",
+			wantOut:     []string{"skipped script 1\n", "archived script 2\n"},
+			wantChanged: []string{"archive 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed = nil
+
+			var out, errOut bytes.Buffer
+			cmd := &cli.Command{
+				Name: "change",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: yesFlag},
+				},
+				Action:    tt.action,
+				Reader:    strings.NewReader(tt.input),
+				Writer:    &out,
+				ErrWriter: &errOut,
+			}
+
+			ctx := context.WithValue(context.Background(), apiClientKey, api)
+			err := cmd.Run(ctx, append([]string{"change"}, tt.args...))
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+			if !slices.Equal(changed, tt.wantChanged) {
+				t.Errorf("expected changes %q, got %q", tt.wantChanged, changed)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got %q", want, out.String())
+				}
+			}
+			for _, want := range tt.wantErrOut {
+				if !strings.Contains(errOut.String(), want) {
+					t.Errorf("expected error output to contain %q, got %q", want, errOut.String())
+				}
+			}
+			if slices.Contains(tt.args, "-yes") && strings.Contains(out.String(), "[y/N]") {
+				t.Errorf("expected no prompt with -yes, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestCreateScriptWithAttachments(t *testing.T) {
+	var actions []string
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		action := r.URL.Query().Get("action")
+		actions = append(actions, action)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch action {
+		case "CreateScript":
+			json.NewEncoder(w).Encode(map[string]any{"id": 60, "title": "backup", "status": "V1"})
+		case "CreateScriptAttachment":
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]any{"message": "try again"})
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	attachment := filepath.Join(t.TempDir(), "exclude.txt")
+	if err := os.WriteFile(attachment, []byte("/tmp\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantErr     string
+		wantActions []string
+	}{
+		{
+			name:        "failed upload names the created script",
+			args:        []string{"script", "create", "-title", "backup", "-code", "#!/bin/sh\ntar c /srv", "-attach", attachment},
+			wantErr:     "script 60 was created, but not with all of its attachments: failed to attach " + attachment + " to script 60",
+			wantActions: []string{"CreateScript", "CreateScriptAttachment"},
+		},
+		{
+			name:    "attachment over the size limit",
+			args:    []string{"-max-attachment-size", "4", "script", "create", "-title", "backup", "-code", "#!/bin/sh\ntar c /srv", "-attach", attachment},
+			wantErr: "attachment too large: " + attachment + " is 5 bytes, the limit is 4 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfig(t)
+			t.Setenv("LANDSCAPE_BASE_URL", server.URL)
+			t.Setenv("LANDSCAPE_TOKEN", "token")
+			t.Setenv("LANDSCAPE_TOKEN_CACHE", filepath.Join(t.TempDir(), "tokens.json"))
+			actions = nil
+
+			_, err := runCLI(t, tt.args...)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+			if !slices.Equal(actions, tt.wantActions) {
+				t.Errorf("expected actions %q, got %q", tt.wantActions, actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"github.com/urfave/cli/v3"
+)
+
+var scriptVersionCmd = &cli.Command{
+	Name:  "version",
+	Usage: "List, compare and revert to the versions of a V2 script.",
+	Commands: []*cli.Command{
+		{
+			Name:          "list",
+			Usage:         "List the versions of a script, with who made each one and when.",
+			ArgsUsage:     "[script-id]",
+			Action:        listScriptVersionsAction,
+			ShellComplete: completeScriptID,
+		},
+		{
+			Name:      "get",
+			Usage:     "Get a version of a script.",
+			ArgsUsage: "[script-id] [version]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  codeFlag,
+					Usage: "Only write the version's code.",
+				},
+			},
+			Action:        getScriptVersionAction,
+			ShellComplete: completeScriptID,
+		},
+		{
+			Name:          "diff",
+			Usage:         "Show the differences between two versions of a script, or between a version and the current one.",
+			ArgsUsage:     "[script-id] [version] [other-version]",
+			Action:        diffScriptVersionsAction,
+			ShellComplete: completeScriptID,
+		},
+		{
+			Name:      "revert",
+			Usage:     "Edit a script to have the code, title and settings of one of its versions, creating a new version.",
+			ArgsUsage: "[script-id] [version]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    yesFlag,
+					Aliases: []string{"y"},
+					Usage:   "Don't ask for confirmation.",
+				},
+			},
+			Action:        revertScriptAction,
+			ShellComplete: completeScriptID,
+		},
+	},
+}
+
+func listScriptVersionsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	versions, err := api.Scripts().Versions(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, versions)
+}
+
+func getScriptVersionAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	version, err := versionArg(cmd, 1)
+	if err != nil {
+		return err
+	}
+
+	v, err := api.Scripts().Version(ctx, scriptID, version)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool(codeFlag) {
+		_, err := io.WriteString(cmd.Root().Writer, v.Source())
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, v)
+}
+
+func diffScriptVersionsAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	from, err := versionArg(cmd, 1)
+	if err != nil {
+		return err
+	}
+
+	to := 0
+	if cmd.Args().Len() > 2 {
+		if to, err = versionArg(cmd, 2); err != nil {
+			return err
+		}
+	} else {
+		script, err := api.Scripts().Get(ctx, scriptID)
+		if err != nil {
+			return err
+		}
+		if to = script.GetVersion(); to == 0 {
+			return fmt.Errorf("script %d is a V1 script, which has no versions", scriptID)
+		}
+	}
+
+	return writeVersionDiff(ctx, cmd.Root().Writer, api, scriptID, from, to)
+}
+
+func revertScriptAction(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+
+	scriptID, err := scriptIDArg(cmd)
+	if err != nil {
+		return err
+	}
+
+	version, err := versionArg(cmd, 1)
+	if err != nil {
+		return err
+	}
+
+	script, err := api.Scripts().Get(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+	if script.GetStatus() != string(client.ACTIVE) {
+		return fmt.Errorf("script %d is %s, only active V2 scripts can be reverted", scriptID, script.GetStatus())
+	}
+	if version == script.GetVersion() {
+		return fmt.Errorf("script %d is already at version %d", scriptID, version)
+	}
+
+	if !cmd.Bool(yesFlag) {
+		out := cmd.Root().Writer
+		if err := writeVersionDiff(ctx, out, api, scriptID, script.GetVersion(), version); err != nil {
+			return err
+		}
+
+		ok, err := confirm(bufio.NewReader(cmd.Root().Reader), out, fmt.Sprintf("Do you want to revert script %d to version %d?", scriptID, version))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintf(out, "skipped script %d\n", scriptID)
+			return nil
+		}
+	}
+
+	reverted, err := api.Scripts().Revert(ctx, scriptID, version)
+	if err != nil {
+		return err
+	}
+
+	return WriteValueToRoot(ctx, cmd, reverted)
+}
+
+// writeVersionDiff writes the differences between two versions of a
+// script.
+func writeVersionDiff(ctx context.Context, w io.Writer, api *client.ClientWithResponses, scriptID, from, to int) error {
+	var docs [2]scriptDocument
+	for i, version := range []int{from, to} {
+		v, err := api.Scripts().Version(ctx, scriptID, version)
+		if err != nil {
+			return fmt.Errorf("failed to get version %d: %w", version, err)
+		}
+		docs[i] = versionDocument(v)
+	}
+
+	name := fmt.Sprintf("script/%d", scriptID)
+	diff := unifiedDiff(fmt.Sprintf("%s (version %d)", name, from), fmt.Sprintf("%s (version %d)", name, to), docs[0].String(), docs[1].String())
+	if diff == "" {
+		diff = "No changes.\n"
+	}
+
+	_, err := io.WriteString(w, diff)
+	return err
+}
+
+// versionDocument returns the text form of a script version. Attachments
+// aren't versioned, so they are left out.
+func versionDocument(v *client.ScriptVersion) scriptDocument {
+	doc := scriptDocument{Title: v.Title, Code: v.Source()}
+	if v.TimeLimit != nil {
+		doc.TimeLimit = *v.TimeLimit
+	}
+	if v.Username != nil {
+		doc.Username = *v.Username
+	}
+	if v.AccessGroup != nil {
+		doc.AccessGroup = *v.AccessGroup
+	}
+	return doc
+}
+
+// versionArg parses the version number given as the command's i-th
+// argument.
+func versionArg(cmd *cli.Command, i int) (int, error) {
+	if cmd.Args().Len() <= i {
+		return 0, fmt.Errorf("version must be provided as argument %d", i+1)
+	}
+
+	version, err := strconv.Atoi(cmd.Args().Get(i))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid version %q: must be a positive number", cmd.Args().Get(i))
+	}
+
+	return version, nil
+}
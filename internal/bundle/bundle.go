@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bundle exports the scripts of a Landscape server into a portable
+// tar.gz archive, and imports them into another server. The archive holds
+// a manifest.json describing the scripts, with their code and attachments
+// stored next to it:
+//
+//	manifest.json
+//	scripts/21433/code
+//	scripts/21433/attachments/0
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+)
+
+// FormatVersion is the version of the archive format written by Export.
+const FormatVersion = 1
+
+const manifestName = "manifest.json"
+
+// Manifest describes the scripts in an archive.
+type Manifest struct {
+	Version int `json:"version"`
+	// Server is the base URL of the server the scripts were exported
+	// from.
+	Server     string    `json:"server,omitempty"`
+	ExportedAt time.Time `json:"exported_at"`
+	Scripts    []Script  `json:"scripts"`
+}
+
+// Script is an exported script.
+type Script struct {
+	// ID is the script's ID on the server it was exported from.
+	ID         int               `json:"id"`
+	Title      string            `json:"title"`
+	ScriptType client.ScriptType `json:"script_type"`
+	// Status is V1, or the V2 script's status: ACTIVE or ARCHIVED.
+	Status string `json:"status"`
+	// CodeFile is the path of the script's code in the archive. The code
+	// starts with a shebang line naming its interpreter.
+	CodeFile    string       `json:"code_file"`
+	TimeLimit   int          `json:"time_limit,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	AccessGroup string       `json:"access_group,omitempty"`
+	Attachments []Attachment `json:"attachments"`
+	// MissingAttachments are the filenames of the attachments that
+	// couldn't be exported and were left out, which only happens with
+	// ExportOptions.SkipFailedAttachments.
+	MissingAttachments []string `json:"missing_attachments,omitempty"`
+}
+
+// Attachment is an exported script attachment.
+type Attachment struct {
+	Filename string `json:"filename"`
+	// File is the path of the attachment's contents in the archive.
+	// Attachments are stored by their position in the script's list,
+	// since filenames may not be valid or unique paths in the archive.
+	File string `json:"file"`
+}
+
+// Bundle is an archive read into memory.
+type Bundle struct {
+	Manifest
+	files map[string][]byte
+}
+
+// Code returns the code of an exported script.
+func (b *Bundle) Code(s Script) string {
+	return string(b.files[s.CodeFile])
+}
+
+// Attachment returns the contents of an exported attachment.
+func (b *Bundle) Attachment(a Attachment) []byte {
+	return b.files[a.File]
+}
+
+// ErrAttachmentExport is returned by Export when an attachment can't be
+// exported.
+var ErrAttachmentExport = errors.New("failed to export attachment")
+
+// ExportOptions are the options for exporting scripts.
+type ExportOptions struct {
+	// Server is the base URL of the server, recorded in the manifest.
+	Server string
+	// SkipFailedAttachments leaves out the attachments that can't be
+	// exported, listing them in the script's MissingAttachments, rather
+	// than failing the export.
+	SkipFailedAttachments bool
+}
+
+// Export writes every script on the server to w as a tar.gz archive, and
+// returns its manifest. Redacted scripts have no code, so they are left
+// out.
+func Export(ctx context.Context, scripts *client.ScriptService, w io.Writer, opts ExportOptions) (*Manifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	writeFile := func(name string, contents []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(contents)),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(contents)
+		return err
+	}
+
+	manifest := &Manifest{Version: FormatVersion, Server: opts.Server, ExportedAt: now, Scripts: []Script{}}
+
+	for script, err := range scripts.All(ctx, client.ListScriptsParams{}) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list scripts: %w", err)
+		}
+		if script.GetStatus() == string(client.REDACTED) {
+			continue
+		}
+
+		s, err := exportScript(ctx, scripts, script, writeFile, opts.SkipFailedAttachments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export script %d: %w", script.GetID(), err)
+		}
+		manifest.Scripts = append(manifest.Scripts, *s)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(manifestName, data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gz.Close()
+}
+
+func exportScript(ctx context.Context, scripts *client.ScriptService, script client.Script, writeFile func(string, []byte) error, skipFailed bool) (*Script, error) {
+	id := script.GetID()
+	dir := fmt.Sprintf("scripts/%d", id)
+
+	s := &Script{
+		ID:          id,
+		Title:       script.GetTitle(),
+		ScriptType:  client.ScriptTypeV2,
+		Status:      script.GetStatus(),
+		CodeFile:    dir + "/code",
+		TimeLimit:   script.GetTimeLimit(),
+		Username:    script.GetUsername(),
+		AccessGroup: script.GetAccessGroup(),
+		Attachments: []Attachment{},
+	}
+	attachments := script.GetAttachments()
+	if _, ok := script.(*client.V1Script); ok {
+		s.ScriptType = client.ScriptTypeV1
+
+		// V1 scripts only list their attachments by filename, so their
+		// IDs are looked up separately.
+		if len(attachments) > 0 {
+			withIDs, err := scripts.ListAttachments(ctx, id)
+			switch {
+			case err == nil:
+				attachments = withIDs
+			case skipFailed:
+				for _, a := range attachments {
+					s.MissingAttachments = append(s.MissingAttachments, a.Filename)
+				}
+				attachments = nil
+			default:
+				return nil, fmt.Errorf("%w: %w", ErrAttachmentExport, err)
+			}
+		}
+	}
+
+	code, err := scripts.GetCode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code: %w", err)
+	}
+	if err := writeFile(s.CodeFile, []byte(code)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		var contents bytes.Buffer
+		if _, err := scripts.DownloadAttachment(ctx, id, a.Id, &contents); err != nil {
+			if skipFailed {
+				s.MissingAttachments = append(s.MissingAttachments, a.Filename)
+				continue
+			}
+			return nil, fmt.Errorf("%w %s: %w", ErrAttachmentExport, a.Filename, err)
+		}
+
+		attachment := Attachment{Filename: a.Filename, File: fmt.Sprintf("%s/attachments/%d", dir, len(s.Attachments))}
+		if err := writeFile(attachment.File, contents.Bytes()); err != nil {
+			return nil, err
+		}
+		s.Attachments = append(s.Attachments, attachment)
+	}
+
+	return s, nil
+}
+
+// Read reads a tar.gz archive written by Export.
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a tar.gz archive: %w", err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{files: map[string][]byte{}}
+	var manifest []byte
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		contents, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == manifestName {
+			manifest = contents
+		} else {
+			b.files[hdr.Name] = contents
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("archive has no %s", manifestName)
+	}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestName, err)
+	}
+	if b.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d, expected %d", b.Version, FormatVersion)
+	}
+
+	for _, s := range b.Scripts {
+		files := []string{s.CodeFile}
+		for _, a := range s.Attachments {
+			if a.Filename == "" || strings.Contains(a.Filename, "/") {
+				return nil, fmt.Errorf("script %d has an invalid attachment filename %q", s.ID, a.Filename)
+			}
+			files = append(files, a.File)
+		}
+		for _, name := range files {
+			if _, ok := b.files[name]; !ok {
+				return nil, fmt.Errorf("script %d: %s is missing from the archive", s.ID, name)
+			}
+		}
+	}
+
+	return b, nil
+}
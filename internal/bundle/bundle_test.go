@@ -0,0 +1,225 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+)
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Fatalf("failed to write response: %v", err)
+	}
+}
+
+// newAPI returns a client for a server with handler.
+func newAPI(t *testing.T, handler http.Handler) *client.ClientWithResponses {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	api, err := client.NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+	return api
+}
+
+func TestExportAndImport(t *testing.T) {
+	source := map[string]map[string]any{
+		"1": {"id": 1, "title": "hello", "status": "V1", "time_limit": 300, "attachments": []string{"old.txt"}},
+		"2": {"id": 2, "title": "backup", "status": "ACTIVE", "version_number": 3, "access_group": "saas-servers",
+			"attachments": []map[string]any{{"id": 7, "filename": "backup.conf"}}},
+		"3": {"id": 3, "title": "retired", "status": "ARCHIVED", "version_number": 1},
+		"4": {"id": 4, "title": "secret", "status": "REDACTED", "version_number": 1},
+		"5": {"id": 5, "title": "gone", "status": "V1", "attachments": []string{"gone.txt"}},
+	}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, "#!/bin/sh\necho "+r.URL.Query().Get("script_id")+"\n")
+	})
+	handler.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+		results := []map[string]any{source["1"], source["2"], source["3"], source["4"], source["5"]}
+		writeJSON(t, w, map[string]any{"count": len(results), "results": results})
+	})
+	handler.HandleFunc("/api/scripts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, source[r.PathValue("id")])
+	})
+	handler.HandleFunc("/api/scripts/2/attachments/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, "dest=/backups\n")
+	})
+	// V1 scripts only list their attachments with IDs here, and the
+	// attachment of script 5 is gone.
+	handler.HandleFunc("/api/scripts/1/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, []map[string]any{{"id": 8, "filename": "old.txt"}})
+	})
+	handler.HandleFunc("/api/scripts/1/attachments/8", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, "old contents\n")
+	})
+	handler.HandleFunc("/api/scripts/5/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, []map[string]any{})
+	})
+	scripts := newAPI(t, handler).Scripts()
+
+	var archive bytes.Buffer
+	if _, err := Export(context.Background(), scripts, &archive, ExportOptions{}); !errors.Is(err, ErrAttachmentExport) {
+		t.Fatalf("expected the export to fail on gone.txt, got %v", err)
+	}
+
+	archive.Reset()
+	manifest, err := Export(context.Background(), scripts, &archive, ExportOptions{Server: "https://saas.example.com", SkipFailedAttachments: true})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(manifest.Scripts) != 4 || manifest.Scripts[0].MissingAttachments != nil || !slices.Equal(manifest.Scripts[3].MissingAttachments, []string{"gone.txt"}) {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	b, err := Read(&archive)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	backup := b.Scripts[1]
+	if backup.ScriptType != client.ScriptTypeV2 || b.Code(backup) != "#!/bin/sh\necho 2\n" || backup.Attachments[0].File != "scripts/2/attachments/0" || string(b.Attachment(backup.Attachments[0])) != "dest=/backups\n" {
+		t.Fatalf("unexpected script %+v", backup)
+	}
+	hello := b.Scripts[0]
+	if hello.ScriptType != client.ScriptTypeV1 || len(hello.Attachments) != 1 || string(b.Attachment(hello.Attachments[0])) != "old contents\n" {
+		t.Fatalf("unexpected script %+v", hello)
+	}
+	if b.Server != "https://saas.example.com" {
+		t.Fatalf("unexpected bundle %+v", b.Manifest)
+	}
+
+	var (
+		created    []string
+		attached   []string
+		archived   []string
+		failAttach = true
+		nextID     = 100
+	)
+
+	target := http.NewServeMux()
+	target.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("action") {
+		case "CreateScript":
+			created = append(created, query.Get("title")+" "+query.Get("script_type")+" "+query.Get("access_group"))
+			nextID++
+			writeJSON(t, w, map[string]any{"id": nextID, "title": query.Get("title"), "status": "ACTIVE", "version_number": 1})
+		case "CreateScriptAttachment":
+			filename, _, _ := strings.Cut(query.Get("file"), "$$")
+			if failAttach && filename == "backup.conf" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"message": "try again"}`))
+				return
+			}
+			attached = append(attached, query.Get("script_id")+" "+filename)
+			writeJSON(t, w, filename)
+		}
+	})
+	target.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+		results := []map[string]any{{"id": 9, "title": "hello", "status": "V1"}}
+		writeJSON(t, w, map[string]any{"count": len(results), "results": results})
+	})
+	target.HandleFunc("/api/scripts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		archived = append(archived, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	scripts = newAPI(t, target).Scripts()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	opts := func() ImportOptions {
+		state, err := LoadState(statePath)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		return ImportOptions{AccessGroups: map[string]string{"saas-servers": "servers"}, OnConflict: ConflictRename, State: state}
+	}
+
+	results, err := Import(context.Background(), scripts, b, opts())
+	if err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	if len(results) != 1 || results[0].Outcome != Renamed || results[0].Title != "hello (2)" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	failAttach = false
+	results, err = Import(context.Background(), scripts, b, opts())
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(results) != 4 || !results[0].Resumed || !results[1].Resumed || results[1].ID != 102 || results[2].Resumed || results[3].Resumed {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	if !slices.Equal(created, []string{"hello (2) V1 ", "backup V2 servers", "retired V2 ", "gone V1 "}) {
+		t.Errorf("unexpected scripts created %q", created)
+	}
+	if !slices.Equal(attached, []string{"101 old.txt", "102 backup.conf"}) {
+		t.Errorf("unexpected attachments %q", attached)
+	}
+	if !slices.Equal(archived, []string{"103:archive"}) {
+		t.Errorf("unexpected archives %q", archived)
+	}
+
+	t.Run("skip conflicts", func(t *testing.T) {
+		results, err := Import(context.Background(), scripts, &Bundle{Manifest: Manifest{Scripts: b.Scripts[:1]}}, ImportOptions{})
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Outcome != Skipped || results[0].ID != 0 {
+			t.Fatalf("unexpected results %+v", results)
+		}
+	})
+
+	t.Run("resume picks up a script whose ID wasn't saved", func(t *testing.T) {
+		var created, attached []string
+
+		target := http.NewServeMux()
+		target.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			switch query.Get("action") {
+			case "CreateScript":
+				created = append(created, query.Get("title"))
+				writeJSON(t, w, map[string]any{"id": 200, "title": query.Get("title"), "status": "ACTIVE", "version_number": 1})
+			case "CreateScriptAttachment":
+				filename, _, _ := strings.Cut(query.Get("file"), "$$")
+				attached = append(attached, query.Get("script_id")+" "+filename)
+				writeJSON(t, w, filename)
+			}
+		})
+		target.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+			results := []map[string]any{{"id": 50, "title": "backup", "status": "ACTIVE", "version_number": 1}}
+			writeJSON(t, w, map[string]any{"count": len(results), "results": results})
+		})
+
+		state := &State{Scripts: map[int]*ImportedScript{2: {Title: "backup", Outcome: Created, Attachments: []string{}}}}
+		results, err := Import(context.Background(), newAPI(t, target).Scripts(), &Bundle{Manifest: Manifest{Scripts: b.Scripts[1:2]}, files: b.files}, ImportOptions{State: state})
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		if len(results) != 1 || results[0].ID != 50 || !results[0].Resumed {
+			t.Fatalf("unexpected results %+v", results)
+		}
+		if created != nil || !slices.Equal(attached, []string{"50 backup.conf"}) {
+			t.Fatalf("expected only the attachment to be added to script 50, got %q created and %q attached", created, attached)
+		}
+	})
+}
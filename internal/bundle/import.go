@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+)
+
+// Conflict is what Import does with a script whose title is already used
+// on the server.
+type Conflict string
+
+const (
+	// ConflictSkip leaves the script out.
+	ConflictSkip Conflict = "skip"
+	// ConflictRename imports the script with a number added to its title,
+	// such as "backup (2)".
+	ConflictRename Conflict = "rename"
+)
+
+// Outcome is what happened to an imported script.
+type Outcome string
+
+const (
+	Created Outcome = "created"
+	Renamed Outcome = "renamed"
+	Skipped Outcome = "skipped"
+)
+
+// Result is the import of one script.
+type Result struct {
+	// SourceID is the script's ID on the server it was exported from.
+	SourceID int `json:"source_id"`
+	// ID is the ID of the script created from it, or zero if it was
+	// skipped.
+	ID      int     `json:"id,omitempty"`
+	Title   string  `json:"title"`
+	Outcome Outcome `json:"outcome"`
+	// Resumed is set for scripts imported, at least partly, by an earlier
+	// run.
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// ImportOptions are the options for importing a bundle.
+type ImportOptions struct {
+	// AccessGroups maps the access groups of the exported scripts to those
+	// of the server. Access groups that aren't mapped are kept.
+	AccessGroups map[string]string
+	// OnConflict defaults to ConflictSkip when empty.
+	OnConflict Conflict
+	// State records the progress of the import, so that it can be resumed
+	// after a failure. It may be nil.
+	State *State
+}
+
+// State is the progress of an import, saved to a file after every step.
+type State struct {
+	path string
+	// Scripts are the imported scripts, keyed by their ID on the server
+	// they were exported from.
+	Scripts map[int]*ImportedScript `json:"scripts"`
+}
+
+// ImportedScript is the progress of importing one script.
+type ImportedScript struct {
+	ID      int     `json:"id,omitempty"`
+	Title   string  `json:"title"`
+	Outcome Outcome `json:"outcome"`
+	// Attachments are the filenames of the attachments added so far.
+	Attachments []string `json:"attachments"`
+	Archived    bool     `json:"archived,omitempty"`
+	Done        bool     `json:"done"`
+}
+
+// LoadState reads the state of an import from path, or returns an empty
+// state that will be saved there if the file doesn't exist.
+func LoadState(path string) (*State, error) {
+	state := &State{path: path, Scripts: map[int]*ImportedScript{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid import state %s: %w", path, err)
+	}
+	if state.Scripts == nil {
+		state.Scripts = map[int]*ImportedScript{}
+	}
+
+	return state, nil
+}
+
+// save writes the state to its file, replacing it only once it's fully
+// written.
+func (s *State) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Import creates the scripts of the bundle on the server, with their
+// attachments, archiving those that were archived. It stops at the first
+// failure and returns the results so far; importing again with the same
+// state resumes where it stopped, without creating scripts twice.
+func Import(ctx context.Context, scripts *client.ScriptService, b *Bundle, opts ImportOptions) ([]Result, error) {
+	state := opts.State
+	if state == nil {
+		state = &State{Scripts: map[int]*ImportedScript{}}
+	}
+	onConflict := opts.OnConflict
+	if onConflict == "" {
+		onConflict = ConflictSkip
+	}
+	if onConflict != ConflictSkip && onConflict != ConflictRename {
+		return nil, fmt.Errorf("conflict handling must be %s or %s, got %q", ConflictSkip, ConflictRename, onConflict)
+	}
+
+	// titles maps the titles used on the server to their scripts' IDs.
+	titles := map[string]int{}
+	for script, err := range scripts.All(ctx, client.ListScriptsParams{}) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list scripts: %w", err)
+		}
+		if status := script.GetStatus(); status == string(client.V1) || status == string(client.ACTIVE) {
+			titles[script.GetTitle()] = script.GetID()
+		}
+	}
+
+	results := []Result{}
+	for _, s := range b.Scripts {
+		imported, resumed := state.Scripts[s.ID]
+		if !resumed {
+			imported = &ImportedScript{Title: s.Title, Outcome: Created, Attachments: []string{}}
+			if _, ok := titles[s.Title]; ok {
+				if onConflict == ConflictSkip {
+					imported.Outcome, imported.Done = Skipped, true
+				} else {
+					imported.Title, imported.Outcome = uniqueTitle(s.Title, titles), Renamed
+				}
+			}
+			state.Scripts[s.ID] = imported
+		} else if id, ok := titles[imported.Title]; ok && imported.ID == 0 && !imported.Done {
+			// The earlier run created the script but stopped before it
+			// could save its ID, so it's picked up rather than created
+			// again.
+			imported.ID = id
+		}
+
+		if err := importScript(ctx, scripts, b, s, imported, state, opts.AccessGroups); err != nil {
+			return results, fmt.Errorf("failed to import script %d %q: %w", s.ID, s.Title, err)
+		}
+		if imported.Outcome != Skipped {
+			titles[imported.Title] = imported.ID
+		}
+
+		results = append(results, Result{SourceID: s.ID, ID: imported.ID, Title: imported.Title, Outcome: imported.Outcome, Resumed: resumed})
+	}
+
+	return results, nil
+}
+
+// importScript does the steps of importing a script that aren't done yet,
+// saving the state after each one.
+func importScript(ctx context.Context, scripts *client.ScriptService, b *Bundle, s Script, imported *ImportedScript, state *State, accessGroups map[string]string) error {
+	if imported.Done {
+		return state.save()
+	}
+
+	if imported.ID == 0 {
+		accessGroup := s.AccessGroup
+		if mapped, ok := accessGroups[accessGroup]; ok {
+			accessGroup = mapped
+		}
+
+		script, err := scripts.Create(ctx, client.CreateScriptParams{
+			Title:       imported.Title,
+			Code:        b.Code(s),
+			TimeLimit:   s.TimeLimit,
+			Username:    s.Username,
+			AccessGroup: accessGroup,
+			ScriptType:  s.ScriptType,
+		})
+		if err != nil {
+			return err
+		}
+		imported.ID = script.GetID()
+		if err := state.save(); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range s.Attachments {
+		if slices.Contains(imported.Attachments, a.Filename) {
+			continue
+		}
+		if _, err := scripts.AddAttachment(ctx, imported.ID, a.Filename, b.Attachment(a)); err != nil {
+			return fmt.Errorf("failed to attach %s: %w", a.Filename, err)
+		}
+		imported.Attachments = append(imported.Attachments, a.Filename)
+		if err := state.save(); err != nil {
+			return err
+		}
+	}
+
+	if s.Status == string(client.ARCHIVED) && !imported.Archived {
+		if err := scripts.Archive(ctx, imported.ID); err != nil {
+			return fmt.Errorf("failed to archive: %w", err)
+		}
+		imported.Archived = true
+	}
+
+	imported.Done = true
+	return state.save()
+}
+
+// uniqueTitle returns title with the lowest number added to it that makes
+// it unused, such as "backup (2)".
+func uniqueTitle(title string, used map[string]int) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", title, n)
+		if _, ok := used[candidate]; !ok {
+			return candidate
+		}
+	}
+}
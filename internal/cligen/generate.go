@@ -0,0 +1,622 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is a CLI command generated for an operation.
+type command struct {
+	Var         string
+	Action      string
+	Name        string
+	OperationID string
+	Method      string
+	Path        string
+	Usage       string
+	Description string
+	ArgsUsage   string
+	Args        []arg
+	Flags       []cliFlag
+	// Params is the type of the operation's parameters struct, if it has
+	// query, header or cookie parameters.
+	Params string
+	Body   *body
+	// Response is the field of the typed response holding the decoded
+	// success body, such as JSON200.
+	Response string
+}
+
+// arg is a path parameter, given as a positional argument.
+type arg struct {
+	Var   string
+	Name  string
+	Parse string
+	// Value is the expression passed to the client method.
+	Value string
+}
+
+// cliFlag is a query, header or cookie parameter or a field of a JSON request
+// body.
+type cliFlag struct {
+	Name     string
+	Kind     string
+	Usage    string
+	Value    string
+	Required bool
+	// In is the variable holding the field the flag sets, params or
+	// body, if it sets one.
+	In string
+	// Target is the field the flag sets, such as params.Limit.
+	Target  string
+	Get     string
+	Pointer bool
+	// Always is set for flags that are assigned even when they aren't
+	// given, so their defaults are sent.
+	Always bool
+}
+
+type body struct {
+	JSON        bool
+	Type        string
+	ContentType string
+}
+
+// value describes how a flag or argument is converted to the Go type that
+// the generated client expects.
+type value struct {
+	// kind is the kind of cli flag: String, Int, Float, Bool, StringSlice,
+	// IntSlice or FloatSlice.
+	kind string
+	typ  string
+}
+
+var flagTypes = map[string]string{
+	"String":      "string",
+	"Int":         "int",
+	"Float":       "float64",
+	"Bool":        "bool",
+	"StringSlice": "[]string",
+	"IntSlice":    "[]int",
+	"FloatSlice":  "[]float64",
+}
+
+// get returns the expression reading the flag called name as v's type.
+func (v value) get(name string) string {
+	expr := fmt.Sprintf("cmd.%s(%q)", v.kind, name)
+	if v.typ == flagTypes[v.kind] {
+		return expr
+	}
+	return v.typ + "(" + expr + ")"
+}
+
+type generator struct {
+	doc      *document
+	warnings io.Writer
+	// usesTypes is set when the generated code refers to the
+	// oapi-codegen runtime types.
+	usesTypes bool
+}
+
+func (g *generator) warn(format string, args ...any) {
+	fmt.Fprintf(g.warnings, "cligen: "+format+"\n", args...)
+}
+
+// commands returns a command for every operation in the document, sorted by
+// name.
+func (g *generator) commands() ([]command, error) {
+	var cmds []command
+
+	for path, item := range g.doc.Paths {
+		if item == nil {
+			continue
+		}
+		for method, op := range item.operations() {
+			if op.OperationID == "" {
+				g.warn("skipping %s %s without an operationId", method, path)
+				continue
+			}
+
+			cmd, err := g.command(path, method, item, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op.OperationID, err)
+			}
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds, nil
+}
+
+func (g *generator) command(path, method string, item *pathItem, op *operation) (command, error) {
+	opID := camelCase(op.OperationID)
+
+	cmd := command{
+		Var:         lowerCamelCase(opID, "") + "APICmd",
+		Action:      lowerCamelCase(opID, "") + "APIAction",
+		Name:        kebabCase(op.OperationID),
+		OperationID: opID,
+		Method:      method,
+		Path:        path,
+		Usage:       usage(op),
+		Description: strings.TrimSpace(op.Description),
+	}
+	if op.Deprecated {
+		cmd.Usage = "(deprecated) " + cmd.Usage
+	}
+
+	params, err := g.parameters(item, op)
+	if err != nil {
+		return cmd, err
+	}
+
+	used := map[string]bool{}
+	var argsUsage []string
+
+	for _, p := range pathOrder(path, params) {
+		a, err := g.arg(opID, p)
+		if err != nil {
+			return cmd, fmt.Errorf("path parameter %s: %w", p.param.Name, err)
+		}
+		cmd.Args = append(cmd.Args, a)
+		argsUsage = append(argsUsage, "["+a.Name+"]")
+	}
+	cmd.ArgsUsage = strings.Join(argsUsage, " ")
+
+	for _, p := range params {
+		if p.param.In == "path" {
+			continue
+		}
+		cmd.Params = opID + "Params"
+
+		f, ok, err := g.paramFlag(opID, p)
+		if err != nil {
+			return cmd, fmt.Errorf("parameter %s: %w", p.param.Name, err)
+		}
+		if !ok {
+			if p.param.Required {
+				return cmd, fmt.Errorf("parameter %s has an unsupported type", p.param.Name)
+			}
+			g.warn("%s: no flag for parameter %s of an unsupported type", opID, p.param.Name)
+			continue
+		}
+		if used[f.Name] {
+			g.warn("%s: no flag for parameter %s, whose name is taken", opID, p.param.Name)
+			continue
+		}
+		used[f.Name] = true
+		cmd.Flags = append(cmd.Flags, f)
+	}
+
+	if op.RequestBody != nil {
+		if err := g.requestBody(&cmd, opID, op.RequestBody, used); err != nil {
+			return cmd, fmt.Errorf("request body: %w", err)
+		}
+	}
+
+	cmd.Response, err = g.responseField(op)
+	if err != nil {
+		return cmd, err
+	}
+
+	return cmd, nil
+}
+
+// usage returns the summary of op, or the first sentence of its
+// description.
+func usage(op *operation) string {
+	if s := oneLine(op.Summary); s != "" {
+		return s
+	}
+
+	desc := oneLine(op.Description)
+	if i := strings.Index(desc, ". "); i >= 0 {
+		desc = desc[:i+1]
+	}
+	if desc != "" {
+		return desc
+	}
+
+	return "Call " + op.OperationID + "."
+}
+
+// namedParameter is a resolved parameter, along with the name of its
+// component if it was a reference.
+type namedParameter struct {
+	param     *parameter
+	component string
+}
+
+// parameters returns the parameters of op, including those of its path
+// that it doesn't override.
+func (g *generator) parameters(item *pathItem, op *operation) ([]namedParameter, error) {
+	var params []namedParameter
+
+	for _, list := range [][]*parameter{op.Parameters, item.Parameters} {
+		for _, p := range list {
+			resolved, component, err := g.doc.parameter(p)
+			if err != nil {
+				return nil, err
+			}
+			if slices.ContainsFunc(params, func(np namedParameter) bool {
+				return np.param.Name == resolved.Name && np.param.In == resolved.In
+			}) {
+				continue
+			}
+			params = append(params, namedParameter{resolved, component})
+		}
+	}
+
+	return params, nil
+}
+
+// pathOrder returns the path parameters in the order they appear in path,
+// which is the order of the client method's arguments.
+func pathOrder(path string, params []namedParameter) []namedParameter {
+	var ordered []namedParameter
+	for _, p := range params {
+		if p.param.In == "path" {
+			ordered = append(ordered, p)
+		}
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return strings.Index(path, "{"+ordered[i].param.Name+"}") < strings.Index(path, "{"+ordered[j].param.Name+"}")
+	})
+	return ordered
+}
+
+func (g *generator) arg(opID string, p namedParameter) (arg, error) {
+	name := p.param.Name
+	if p.param.GoName != "" {
+		name = p.param.GoName
+	}
+
+	a := arg{
+		Var:  lowerCamelCase(name, "Arg"),
+		Name: kebabCase(p.param.Name),
+	}
+
+	if p.param.Schema == nil {
+		return a, fmt.Errorf("missing schema")
+	}
+	v, ok, err := g.value(p.param.Schema, opID+"Params"+camelCase(name))
+	if err != nil {
+		return a, err
+	}
+	if !ok {
+		return a, fmt.Errorf("unsupported type")
+	}
+	if p.component != "" {
+		v.typ = "client." + p.component
+	}
+
+	switch v.kind {
+	case "String":
+		a.Parse = "stringArg"
+	case "Int":
+		a.Parse = "intArg"
+	case "Float":
+		a.Parse = "floatArg"
+	case "Bool":
+		a.Parse = "boolArg"
+	default:
+		return a, fmt.Errorf("unsupported type")
+	}
+
+	a.Value = a.Var
+	if v.typ != flagTypes[v.kind] {
+		a.Value = v.typ + "(" + a.Var + ")"
+	}
+
+	return a, nil
+}
+
+func (g *generator) paramFlag(opID string, p namedParameter) (cliFlag, bool, error) {
+	fieldName := camelCase(p.param.Name)
+	if p.param.GoName != "" {
+		fieldName = p.param.GoName
+	}
+
+	if p.param.Schema == nil {
+		return cliFlag{}, false, nil
+	}
+	v, ok, err := g.value(p.param.Schema, opID+"Params"+fieldName)
+	if err != nil || !ok {
+		return cliFlag{}, false, err
+	}
+	if p.component != "" {
+		v.typ = "client." + p.component
+	}
+
+	resolved, _, err := g.doc.schema(p.param.Schema)
+	if err != nil {
+		return cliFlag{}, false, err
+	}
+
+	f := cliFlag{
+		Name:    kebabCase(p.param.Name),
+		Kind:    v.kind,
+		Usage:   flagUsage(p.param.Description, resolved, false),
+		Value:   defaultValue(v.kind, resolved.Default),
+		In:      "params",
+		Target:  "params." + fieldName,
+		Pointer: !p.param.Required,
+		Always:  p.param.Required,
+	}
+	f.Required = p.param.Required && f.Value == ""
+	f.Get = v.get(f.Name)
+
+	return f, true, nil
+}
+
+func (g *generator) requestBody(cmd *command, opID string, rb *requestBody, used map[string]bool) error {
+	rb, err := g.doc.requestBody(rb)
+	if err != nil {
+		return err
+	}
+
+	media, ok := rb.Content["application/json"]
+	if !ok {
+		var types []string
+		for contentType := range rb.Content {
+			types = append(types, contentType)
+		}
+		if len(types) == 0 {
+			return nil
+		}
+		sort.Strings(types)
+
+		cmd.Body = &body{ContentType: types[0]}
+		cmd.Flags = append(cmd.Flags, cliFlag{
+			Name:  "body-file",
+			Kind:  "String",
+			Usage: fmt.Sprintf("The file to send as the %s request body, or - for stdin.", types[0]),
+		})
+		return nil
+	}
+
+	cmd.Body = &body{JSON: true, Type: opID + "JSONRequestBody"}
+	cmd.Flags = append(cmd.Flags, cliFlag{
+		Name:  "body",
+		Kind:  "String",
+		Usage: "The request body as JSON. The flags for its fields are applied on top of it.",
+	})
+	used["body"] = true
+
+	if media.Schema == nil {
+		return nil
+	}
+	s, name, err := g.doc.schema(media.Schema)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = opID + "JSONBody"
+	}
+	if s.GoName != "" {
+		name = s.GoName
+	}
+
+	props := make([]string, 0, len(s.Properties))
+	for prop := range s.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	for _, prop := range props {
+		ps := s.Properties[prop]
+
+		fieldName := camelCase(prop)
+		if ps.GoName != "" {
+			fieldName = ps.GoName
+		}
+
+		v, ok, err := g.value(ps, name+fieldName)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", prop, err)
+		}
+		if !ok {
+			g.warn("%s: no flag for body field %s of an unsupported type", opID, prop)
+			continue
+		}
+
+		f := cliFlag{Name: kebabCase(prop), Kind: v.kind}
+		if used[f.Name] {
+			g.warn("%s: no flag for body field %s, whose name is taken", opID, prop)
+			continue
+		}
+		used[f.Name] = true
+
+		resolved, _, err := g.doc.schema(ps)
+		if err != nil {
+			return err
+		}
+
+		nullable := ps.Nullable || resolved.Nullable || resolved.Type.nullable()
+		required := slices.Contains(s.Required, prop)
+
+		description := resolved.Description
+		if ps.Description != "" {
+			description = ps.Description
+		}
+		f.Usage = flagUsage(description, resolved, required && !nullable)
+		f.In = "body"
+		f.Target = "body." + fieldName
+		f.Get = v.get(f.Name)
+		f.Pointer = !required || nullable
+
+		cmd.Flags = append(cmd.Flags, f)
+	}
+
+	return nil
+}
+
+// responseField returns the field of the typed response that holds the
+// first JSON success response, if there is one.
+func (g *generator) responseField(op *operation) (string, error) {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		res, err := g.doc.response(op.Responses[code])
+		if err != nil {
+			return "", err
+		}
+		if _, ok := res.Content["application/json"]; ok {
+			return "JSON" + code, nil
+		}
+	}
+
+	return "", nil
+}
+
+// value returns how a flag or argument with schema s is converted to the
+// type of the generated client. inlineName is the type that oapi-codegen
+// gives s if it's an inline enum. It reports false for types without a
+// flag, such as objects and timestamps.
+func (g *generator) value(s *schema, inlineName string) (value, bool, error) {
+	resolved, name, err := g.doc.schema(s)
+	if err != nil {
+		return value{}, false, err
+	}
+	if resolved.GoType != nil {
+		return value{}, false, nil
+	}
+
+	v, ok := primitiveValue(resolved)
+	if !ok {
+		return value{}, false, nil
+	}
+
+	switch {
+	case name != "":
+		if resolved.GoName != "" {
+			name = resolved.GoName
+		}
+		v.typ = "client." + name
+	case len(resolved.Enum) > 0:
+		v.typ = "client." + inlineName
+	case strings.HasPrefix(v.typ, "openapi_types."):
+		g.usesTypes = true
+	}
+
+	return v, true, nil
+}
+
+// primitiveValue returns the flag kind and Go type of a string, number,
+// boolean or list of them, following oapi-codegen's type mapping.
+func primitiveValue(s *schema) (value, bool) {
+	switch s.Type.name() {
+	case "string":
+		switch s.Format {
+		case "date", "date-time", "uuid", "binary", "byte":
+			return value{}, false
+		case "email":
+			return value{"String", "openapi_types.Email"}, true
+		}
+		return value{"String", "string"}, true
+	case "integer":
+		switch s.Format {
+		case "int32", "int64":
+			return value{"Int", s.Format}, true
+		}
+		return value{"Int", "int"}, true
+	case "number":
+		if s.Format == "double" {
+			return value{"Float", "float64"}, true
+		}
+		return value{"Float", "float32"}, true
+	case "boolean":
+		return value{"Bool", "bool"}, true
+	case "array":
+		if s.Items == nil || s.Items.Ref != "" || len(s.Items.Enum) > 0 {
+			return value{}, false
+		}
+		item, ok := primitiveValue(s.Items)
+		if !ok {
+			return value{}, false
+		}
+		for kind, typ := range map[string]string{"StringSlice": "string", "IntSlice": "int", "FloatSlice": "float64"} {
+			if item.typ == typ {
+				return value{kind, "[]" + typ}, true
+			}
+		}
+	}
+
+	return value{}, false
+}
+
+// flagUsage returns the usage text of a flag from its description, noting
+// the values it accepts.
+func flagUsage(description string, s *schema, required bool) string {
+	usage := oneLine(description)
+	if usage != "" && !strings.HasSuffix(usage, ".") {
+		usage += "."
+	}
+
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		usage += " One of: " + strings.Join(values, ", ") + "."
+	}
+
+	if required {
+		usage += " Required."
+	}
+
+	return strings.TrimSpace(usage)
+}
+
+// defaultValue returns the default as a Go literal for a flag of the given
+// kind, or "" if there isn't one.
+func defaultValue(kind string, def any) string {
+	if def == nil {
+		return ""
+	}
+
+	switch kind {
+	case "String":
+		if t, ok := def.(time.Time); ok {
+			// YAML reads unquoted dates, such as legacy API versions, as
+			// timestamps.
+			if t.Equal(t.Truncate(24 * time.Hour)) {
+				return strconv.Quote(t.Format(time.DateOnly))
+			}
+			return strconv.Quote(t.Format(time.RFC3339))
+		}
+		return strconv.Quote(fmt.Sprint(def))
+	case "Int":
+		if n, ok := def.(int); ok {
+			return strconv.Itoa(n)
+		}
+	case "Float":
+		switch n := def.(type) {
+		case int:
+			return strconv.Itoa(n)
+		case float64:
+			return strconv.FormatFloat(n, 'g', -1, 64)
+		}
+	case "Bool":
+		if b, ok := def.(bool); ok {
+			return strconv.FormatBool(b)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Command cligen generates the api commands of the landscape-api CLI from
+// the Landscape API OpenAPI spec, with a command for every operation of the
+// client that oapi-codegen generates from the same spec. Path parameters
+// become arguments, and query parameters and the fields of JSON request
+// bodies become flags.
+//
+// Usage:
+//
+//	go run ./internal/cligen -o cmd/landscape-api/api.gen.go landscape_api.bundle.yaml
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"text/template"
+)
+
+func main() {
+	out := flag.String("o", "api.gen.go", "The file to write the generated commands to.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: cligen [-o output] spec\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *out, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "cligen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, outPath string, warnings io.Writer) error {
+	doc, err := loadDocument(specPath)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(doc, warnings)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outPath, src, 0o644)
+}
+
+// generate returns the formatted source of the commands for doc.
+func generate(doc *document, warnings io.Writer) ([]byte, error) {
+	g := &generator{doc: doc, warnings: warnings}
+
+	cmds, err := g.commands()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := commandsTemplate.Execute(&buf, map[string]any{
+		"Commands":  cmds,
+		"UsesTypes": g.usesTypes,
+	}); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, buf.Bytes())
+	}
+
+	return src, nil
+}
+
+var commandsTemplate = template.Must(template.New("commands").Parse(`// Code generated by cligen. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+{{- if .UsesTypes}}
+	openapi_types "github.com/oapi-codegen/runtime/types"
+{{- end}}
+	"github.com/urfave/cli/v3"
+)
+
+// apiCommands has a command for every operation of the API client.
+var apiCommands = []*cli.Command{
+{{- range .Commands}}
+	{{.Var}},
+{{- end}}
+}
+{{range .Commands}}
+// {{.Var}} calls {{.OperationID}} ({{.Method}} {{.Path}}).
+var {{.Var}} = &cli.Command{
+	Name:  {{printf "%q" .Name}},
+	Usage: {{printf "%q" .Usage}},
+{{- if .Description}}
+	Description: {{printf "%q" .Description}},
+{{- end}}
+{{- if .ArgsUsage}}
+	ArgsUsage: {{printf "%q" .ArgsUsage}},
+{{- end}}
+{{- if .Flags}}
+	Flags: []cli.Flag{
+{{- range .Flags}}
+		&cli.{{.Kind}}Flag{
+			Name:  {{printf "%q" .Name}},
+			Usage: {{printf "%q" .Usage}},
+{{- if .Value}}
+			Value: {{.Value}},
+{{- end}}
+{{- if .Required}}
+			Required: true,
+{{- end}}
+		},
+{{- end}}
+	},
+{{- end}}
+	Action: {{.Action}},
+}
+
+func {{.Action}}(ctx context.Context, cmd *cli.Command) error {
+	api, ok := ctx.Value(apiClientKey).(*client.ClientWithResponses)
+	if !ok || api == nil {
+		return fmt.Errorf("api client not initialized")
+	}
+{{range $i, $a := .Args}}
+	{{$a.Var}}, err := {{$a.Parse}}(cmd, {{$i}}, {{printf "%q" $a.Name}})
+	if err != nil {
+		return err
+	}
+{{end}}
+{{- if .Params}}
+	params := &client.{{.Params}}{}
+{{- range .Flags}}{{if eq .In "params"}}{{template "assign" .}}{{end}}{{end}}
+{{end}}
+{{- with .Body}}{{if .JSON}}
+	var body client.{{.Type}}
+	if err := decodeAPIBody(cmd, "body", &body); err != nil {
+		return err
+	}
+{{- else}}
+	bodyReader, err := openAPIBody(cmd, "body-file")
+	if err != nil {
+		return err
+	}
+	defer bodyReader.Close()
+{{- end}}{{end}}
+{{- if and .Body .Body.JSON}}
+{{- range .Flags}}{{if eq .In "body"}}{{template "assign" .}}{{end}}{{end}}
+{{end}}
+	res, err := api.{{.OperationID}}{{if and .Body (not .Body.JSON)}}WithBody{{end}}WithResponse(ctx
+{{- range .Args}}, {{.Value}}{{end}}
+{{- if .Params}}, params{{end}}
+{{- with .Body}}{{if .JSON}}, body{{else}}, {{printf "%q" .ContentType}}, bodyReader{{end}}{{end}})
+	if err != nil {
+		return fmt.Errorf("failed to call {{.OperationID}}: %w", err)
+	}
+
+	return WriteTypedResponseToRoot(ctx, cmd, res.HTTPResponse, res.Body, {{if .Response}}res.{{.Response}}{{else}}nil{{end}})
+}
+{{end}}
+{{- define "assign"}}
+{{- if .Always}}
+	{{.Target}} = {{.Get}}
+{{- else if .Pointer}}
+	if cmd.IsSet({{printf "%q" .Name}}) {
+		v := {{.Get}}
+		{{.Target}} = &v
+	}
+{{- else}}
+	if cmd.IsSet({{printf "%q" .Name}}) {
+		{{.Target}} = {{.Get}}
+	}
+{{- end}}
+{{- end}}
+`))
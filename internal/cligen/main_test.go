@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerate(t *testing.T) {
+	doc, err := loadDocument(filepath.Join("testdata", "landscape_api.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	var warnings bytes.Buffer
+	src, err := generate(doc, &warnings)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if warnings.Len() > 0 {
+		t.Fatalf("unexpected warnings: %s", warnings.String())
+	}
+
+	for _, want := range []string{
+		`scriptId, err := intArg(cmd, 0, "script-id")`,
+		`attachmentId, err := intArg(cmd, 1, "attachment-id")`,
+		`api.GetScriptAttachmentWithResponse(ctx, client.ScriptIdPathParam(scriptId), client.ScriptAttachmentIdPathParam(attachmentId))`,
+		`params.Version = client.LegacyVersionParam(cmd.String("version"))`,
+		`Value: "2011-08-01",`,
+		`body.Email = openapi_types.Email(cmd.String("email"))`,
+		`body.Account = &v`,
+		`res.HTTPResponse, res.Body, res.JSON200)`,
+		`api.ArchiveScriptWithResponse(ctx, client.ScriptIdPathParam(scriptId))`,
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("generated code doesn't contain %q", want)
+		}
+	}
+}
+
+func TestGenerateParameters(t *testing.T) {
+	const spec = `
+paths:
+  /api/computers:
+    get:
+      operationId: listComputers
+      parameters:
+        - name: tags
+          in: query
+          schema: {type: array, items: {type: string}}
+        - name: limit
+          in: query
+          description: The number of computers to return
+          schema: {type: integer, format: int64, default: 100}
+        - name: status
+          in: query
+          schema: {type: string, enum: [online, offline]}
+        - name: since
+          in: query
+          schema: {type: string, format: date-time}
+      responses:
+        '200':
+          description: The computers.
+  /api/computers/{name}/files:
+    put:
+      operationId: UploadFile
+      parameters:
+        - name: name
+          in: path
+          required: true
+          schema: {type: string}
+      requestBody:
+        content:
+          application/octet-stream:
+            schema: {type: string, format: binary}
+      responses:
+        '204':
+          description: The file was uploaded.
+`
+
+	var doc document
+	if err := yaml.Unmarshal([]byte(spec), &doc); err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	var warnings bytes.Buffer
+	src, err := generate(&doc, &warnings)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`Name:  "list-computers"`,
+		`Usage: "Call listComputers."`,
+		`v := cmd.StringSlice("tags")`,
+		`v := int64(cmd.Int("limit"))`,
+		`Value: 100,`,
+		`Usage: "The number of computers to return."`,
+		`v := client.ListComputersParamsStatus(cmd.String("status"))`,
+		`Usage: "One of: online, offline."`,
+		`name, err := stringArg(cmd, 0, "name")`,
+		`bodyReader, err := openAPIBody(cmd, "body-file")`,
+		`api.UploadFileWithBodyWithResponse(ctx, name, "application/octet-stream", bodyReader)`,
+		`res.HTTPResponse, res.Body, nil)`,
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("generated code doesn't contain %q", want)
+		}
+	}
+
+	if !strings.Contains(warnings.String(), "no flag for parameter since") {
+		t.Errorf("expected a warning about the since parameter, got %q", warnings.String())
+	}
+}
+
+func TestNames(t *testing.T) {
+	for name, want := range map[string]string{
+		"GetScriptAttachment": "get-script-attachment",
+		"listComputers":       "list-computers",
+		"GetAPIKey":           "get-api-key",
+		"script_id":           "script-id",
+		"access-key":          "access-key",
+	} {
+		if got := kebabCase(name); got != want {
+			t.Errorf("kebabCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"script_id":     "ScriptId",
+		"getScript":     "GetScript",
+		"x.api-version": "XApiVersion",
+	} {
+		if got := camelCase(name); got != want {
+			t.Errorf("camelCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if got := lowerCamelCase("type", "Arg"); got != "typeArg" {
+		t.Errorf("expected the keyword type to get a suffix, got %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// camelCase converts an OpenAPI name into the exported Go name that
+// oapi-codegen gives it: separators are dropped and the letter after each
+// one is capitalized, so script_id becomes ScriptId.
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// lowerCamelCase is camelCase with a lowercase first letter, for unexported
+// identifiers. Names that would clash with Go keywords or the variables of
+// the generated actions get a suffix.
+func lowerCamelCase(name string, suffix string) string {
+	name = camelCase(name)
+	if name == "" {
+		return name
+	}
+
+	name = strings.ToLower(name[:1]) + name[1:]
+	if token.IsKeyword(name) || reservedIdents[name] {
+		name += suffix
+	}
+
+	return name
+}
+
+// reservedIdents are the identifiers used by the generated actions.
+var reservedIdents = map[string]bool{
+	"api":        true,
+	"body":       true,
+	"bodyReader": true,
+	"client":     true,
+	"cli":        true,
+	"cmd":        true,
+	"ctx":        true,
+	"err":        true,
+	"fmt":        true,
+	"ok":         true,
+	"params":     true,
+	"res":        true,
+}
+
+// kebabCase converts an operation ID or parameter name into a command or
+// flag name, so GetScriptAttachment becomes get-script-attachment and
+// script_id becomes script-id.
+func kebabCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+				b.WriteRune('-')
+			}
+			continue
+		}
+
+		if unicode.IsUpper(r) && i > 0 && b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('-')
+			}
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// oneLine collapses the whitespace in text, such as a multi-line
+// description, so it can be used as usage text.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
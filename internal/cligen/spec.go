@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is the part of an OpenAPI 3 document that the generator needs.
+type document struct {
+	Paths      map[string]*pathItem `yaml:"paths"`
+	Components components           `yaml:"components"`
+}
+
+type components struct {
+	Schemas       map[string]*schema      `yaml:"schemas"`
+	Parameters    map[string]*parameter   `yaml:"parameters"`
+	RequestBodies map[string]*requestBody `yaml:"requestBodies"`
+	Responses     map[string]*response    `yaml:"responses"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Put        *operation   `yaml:"put"`
+	Post       *operation   `yaml:"post"`
+	Delete     *operation   `yaml:"delete"`
+	Options    *operation   `yaml:"options"`
+	Head       *operation   `yaml:"head"`
+	Patch      *operation   `yaml:"patch"`
+	Trace      *operation   `yaml:"trace"`
+}
+
+// operations returns the operations of the path by their HTTP method.
+func (p *pathItem) operations() map[string]*operation {
+	ops := map[string]*operation{}
+	for method, op := range map[string]*operation{
+		"GET":     p.Get,
+		"PUT":     p.Put,
+		"POST":    p.Post,
+		"DELETE":  p.Delete,
+		"OPTIONS": p.Options,
+		"HEAD":    p.Head,
+		"PATCH":   p.Patch,
+		"TRACE":   p.Trace,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+type operation struct {
+	OperationID string               `yaml:"operationId"`
+	Summary     string               `yaml:"summary"`
+	Description string               `yaml:"description"`
+	Deprecated  bool                 `yaml:"deprecated"`
+	Parameters  []*parameter         `yaml:"parameters"`
+	RequestBody *requestBody         `yaml:"requestBody"`
+	Responses   map[string]*response `yaml:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *schema `yaml:"schema"`
+	GoName      string  `yaml:"x-go-name"`
+}
+
+type requestBody struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Required    bool                  `yaml:"required"`
+	Content     map[string]*mediaType `yaml:"content"`
+}
+
+type response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref         string             `yaml:"$ref"`
+	Type        schemaType         `yaml:"type"`
+	Format      string             `yaml:"format"`
+	Description string             `yaml:"description"`
+	Enum        []any              `yaml:"enum"`
+	Default     any                `yaml:"default"`
+	Nullable    bool               `yaml:"nullable"`
+	Items       *schema            `yaml:"items"`
+	Properties  map[string]*schema `yaml:"properties"`
+	Required    []string           `yaml:"required"`
+	AllOf       []*schema          `yaml:"allOf"`
+	OneOf       []*schema          `yaml:"oneOf"`
+	AnyOf       []*schema          `yaml:"anyOf"`
+	GoName      string             `yaml:"x-go-name"`
+	GoType      any                `yaml:"x-go-type"`
+}
+
+// schemaType is the type of a schema, which OpenAPI 3.1 allows to be a list
+// such as [string, "null"].
+type schemaType []string
+
+func (t *schemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = schemaType{node.Value}
+		return nil
+	}
+
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// name returns the type other than "null".
+func (t schemaType) name() string {
+	for _, name := range t {
+		if name != "null" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (t schemaType) nullable() bool {
+	for _, name := range t {
+		if name == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func loadDocument(path string) (*document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return &doc, nil
+}
+
+// lookupRef returns the component that ref points to, along with its name.
+// Only references within the document are supported, which is all a bundle
+// has.
+func lookupRef[T any](ref, section string, components map[string]*T) (*T, string, error) {
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return nil, "", fmt.Errorf("unsupported reference %q", ref)
+	}
+
+	name := strings.TrimPrefix(ref, prefix)
+	c, ok := components[name]
+	if !ok || c == nil {
+		return nil, "", fmt.Errorf("missing component %q", ref)
+	}
+
+	return c, name, nil
+}
+
+func (d *document) parameter(p *parameter) (*parameter, string, error) {
+	if p.Ref == "" {
+		return p, "", nil
+	}
+	return lookupRef(p.Ref, "parameters", d.Components.Parameters)
+}
+
+func (d *document) requestBody(b *requestBody) (*requestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	b, _, err := lookupRef(b.Ref, "requestBodies", d.Components.RequestBodies)
+	return b, err
+}
+
+func (d *document) response(r *response) (*response, error) {
+	if r.Ref == "" {
+		return r, nil
+	}
+	r, _, err := lookupRef(r.Ref, "responses", d.Components.Responses)
+	return r, err
+}
+
+// schema follows s through any references, returning the schema it ends at
+// and the name of the first component on the way, which is the name of its
+// generated type.
+func (d *document) schema(s *schema) (*schema, string, error) {
+	var name string
+
+	for seen := 0; s.Ref != ""; seen++ {
+		if seen > len(d.Components.Schemas) {
+			return nil, "", fmt.Errorf("reference cycle at %q", s.Ref)
+		}
+
+		next, n, err := lookupRef(s.Ref, "schemas", d.Components.Schemas)
+		if err != nil {
+			return nil, "", err
+		}
+		if name == "" {
+			name = n
+		}
+		s = next
+	}
+
+	return s, name, nil
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package manifest reads declarative script manifests and reconciles the
+// scripts on a Landscape server with them. A manifest is a YAML document
+// describing one script:
+//
+//	id: 21433 # optional, matches the script by ID instead of by title
+//	title: backup
+//	code_file: backup.sh
+//	interpreter: /bin/bash
+//	time_limit: 300
+//	username: root
+//	access_group: global
+//	attachments:
+//	  - backup.conf
+//
+// Paths are relative to the manifest's file. Fields that are left out
+// aren't managed, so the script's current values are kept.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a script as it should be on the server.
+type Manifest struct {
+	// ID matches the manifest to the script with this ID. Without it, the
+	// manifest is matched to the active script with the same title.
+	ID    int    `yaml:"id,omitempty"`
+	Title string `yaml:"title"`
+	// CodeFile is the file holding the script's code.
+	CodeFile string `yaml:"code_file"`
+	// Interpreter is added as a shebang line to code that has none.
+	Interpreter string `yaml:"interpreter,omitempty"`
+	// ScriptType is only used when the script is created, and defaults
+	// to V1 as with script create.
+	ScriptType  client.ScriptType `yaml:"script_type,omitempty"`
+	TimeLimit   *int              `yaml:"time_limit,omitempty"`
+	Username    *string           `yaml:"username,omitempty"`
+	AccessGroup *string           `yaml:"access_group,omitempty"`
+	// Attachments are the files that should be attached to the script.
+	// When set, even to an empty list, attachments that aren't listed are
+	// removed.
+	Attachments []string `yaml:"attachments,omitempty"`
+
+	// Path is the file the manifest was read from.
+	Path string `yaml:"-"`
+	// Code is the contents of CodeFile, starting with a shebang line.
+	Code string `yaml:"-"`
+	// Files are the contents of Attachments.
+	Files []File `yaml:"-"`
+}
+
+// File is a local file to attach to a script.
+type File struct {
+	Path     string
+	Name     string
+	Contents []byte
+}
+
+// Load reads the manifests in the file at path, or in every .yaml and .yml
+// file under it if it's a directory, along with their code and
+// attachments. A file may hold several manifests separated by ---.
+func Load(path string) ([]*Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(p); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var manifests []*Manifest
+	for _, file := range files {
+		m, err := loadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m...)
+	}
+
+	if err := checkUnique(manifests); err != nil {
+		return nil, err
+	}
+
+	return manifests, nil
+}
+
+// loadFile reads the manifests in a file.
+func loadFile(path string) ([]*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var manifests []*Manifest
+	for {
+		var m Manifest
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		m.Path = path
+		if err := m.load(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		manifests = append(manifests, &m)
+	}
+}
+
+// load checks the manifest and reads its code and attachments, relative to
+// dir.
+func (m *Manifest) load(dir string) error {
+	if m.Title == "" {
+		return fmt.Errorf("title must be set")
+	}
+	if m.CodeFile == "" {
+		return fmt.Errorf("code_file must be set")
+	}
+	switch m.ScriptType {
+	case "", client.ScriptTypeV1, client.ScriptTypeV2:
+	default:
+		return fmt.Errorf("script_type must be V1 or V2")
+	}
+
+	code, err := os.ReadFile(filepath.Join(dir, m.CodeFile))
+	if err != nil {
+		return fmt.Errorf("failed to read code: %w", err)
+	}
+	m.Code = string(code)
+
+	shebang := client.ShebangInterpreter(m.Code)
+	switch {
+	case shebang == "" && m.Interpreter == "":
+		return fmt.Errorf("%s has no shebang line, so interpreter must be set", m.CodeFile)
+	case shebang != "" && m.Interpreter != "" && shebang != m.Interpreter:
+		return fmt.Errorf("interpreter %q doesn't match the shebang line of %s %q", m.Interpreter, m.CodeFile, shebang)
+	case shebang == "":
+		m.Code = client.SetShebang(m.Code, m.Interpreter)
+	}
+
+	for _, a := range m.Attachments {
+		path := filepath.Join(dir, a)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read attachment: %w", err)
+		}
+		if info.Size() > client.MaxAttachmentSize {
+			return fmt.Errorf("%w: %s is %d bytes, the limit is %d bytes", client.ErrAttachmentTooLarge, a, info.Size(), client.MaxAttachmentSize)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read attachment: %w", err)
+		}
+
+		name := filepath.Base(a)
+		if slices.ContainsFunc(m.Files, func(f File) bool { return f.Name == name }) {
+			return fmt.Errorf("more than one attachment is called %s", name)
+		}
+		m.Files = append(m.Files, File{Path: path, Name: name, Contents: contents})
+	}
+
+	return nil
+}
+
+// checkUnique checks that no two manifests have the same ID, or the same
+// title without an ID. A manifest with an ID can still describe the same
+// script as one matched by its title, which NewPlan checks once it knows
+// the scripts.
+func checkUnique(manifests []*Manifest) error {
+	ids := map[int]string{}
+	titles := map[string]string{}
+
+	for _, m := range manifests {
+		if m.ID != 0 {
+			if other, ok := ids[m.ID]; ok {
+				return fmt.Errorf("%s and %s both have id %d", other, m.Path, m.ID)
+			}
+			ids[m.ID] = m.Path
+			continue
+		}
+
+		if other, ok := titles[m.Title]; ok {
+			return fmt.Errorf("%s and %s both have title %q; set id to tell them apart", other, m.Path, m.Title)
+		}
+		titles[m.Title] = m.Path
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+)
+
+// writeFiles writes files, keyed by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"hello.yaml": "title: hello\ncode_file: hello.sh\ninterpreter: /bin/sh\nattachments: []\n",
+		"hello.sh":   "echo hello\n",
+		"more/scripts.yml": "title: backup\ncode_file: backup.sh\ntime_limit: 600\nattachments: [conf/backup.conf]\n" +
+			"---\nid: 12\ntitle: backup\ncode_file: backup.sh\n",
+		"more/backup.sh":          "#!/bin/bash\ntar c /srv\n",
+		"more/conf/backup.conf":   "dest=/backups\n",
+		"more/conf/ignored.json":  "{}",
+		"more/conf/not-a-yaml.sh": "exit 1\n",
+	})
+
+	manifests, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(manifests) != 3 {
+		t.Fatalf("expected 3 manifests, got %d", len(manifests))
+	}
+
+	hello := manifests[0]
+	if hello.Title != "hello" || hello.Code != "#!/bin/sh\necho hello\n" {
+		t.Errorf("unexpected manifest %+v", hello)
+	}
+	if hello.Attachments == nil || hello.Username != nil {
+		t.Errorf("expected attachments to be managed and the username not to be, got %+v", hello)
+	}
+
+	backup := manifests[1]
+	if *backup.TimeLimit != 600 || len(backup.Files) != 1 || backup.Files[0].Name != "backup.conf" || string(backup.Files[0].Contents) != "dest=/backups\n" {
+		t.Errorf("unexpected manifest %+v", backup)
+	}
+	if manifests[2].ID != 12 || manifests[2].Attachments != nil {
+		t.Errorf("unexpected manifest %+v", manifests[2])
+	}
+
+	for name, tt := range map[string]struct {
+		files map[string]string
+		err   string
+	}{
+		"no title": {
+			files: map[string]string{"a.yaml": "code_file: a.sh\n", "a.sh": "#!/bin/sh\n"},
+			err:   "title must be set",
+		},
+		"no interpreter": {
+			files: map[string]string{"a.yaml": "title: a\ncode_file: a.sh\n", "a.sh": "echo\n"},
+			err:   "interpreter must be set",
+		},
+		"mismatched interpreter": {
+			files: map[string]string{"a.yaml": "title: a\ncode_file: a.sh\ninterpreter: /bin/sh\n", "a.sh": "#!/bin/bash\n"},
+			err:   "doesn't match",
+		},
+		"unknown field": {
+			files: map[string]string{"a.yaml": "title: a\ncode_file: a.sh\ntimelimit: 3\n", "a.sh": "#!/bin/sh\n"},
+			err:   "timelimit",
+		},
+		"duplicate title": {
+			files: map[string]string{"a.yaml": "title: a\ncode_file: a.sh\n---\ntitle: a\ncode_file: a.sh\n", "a.sh": "#!/bin/sh\n"},
+			err:   `both have title "a"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	scripts := map[string]map[string]any{
+		"1": {"id": 1, "title": "hello", "status": "V1", "time_limit": 300, "attachments": []string{"old.txt"}},
+		"2": {"id": 2, "title": "backup", "status": "ACTIVE", "version_number": 1, "time_limit": 60,
+			"attachments": []map[string]any{{"id": 7, "filename": "backup.conf"}}},
+		"3": {"id": 3, "title": "stale", "status": "ACTIVE", "version_number": 1},
+		"4": {"id": 4, "title": "gone", "status": "ARCHIVED", "version_number": 2},
+		"6": {"id": 6, "title": "legacy", "status": "V1", "attachments": []string{"same.txt", "changed.txt"}},
+	}
+	codes := map[string]string{
+		"1": "#!/bin/sh\necho hello\n",
+		"2": "#!/bin/bash\ntar c /srv\n",
+		"3": "#!/bin/sh\n",
+		"6": "#!/bin/sh\necho legacy\n",
+	}
+
+	var (
+		actions  []string
+		archived []string
+	)
+
+	handler := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}
+	handler.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		action := query.Get("action")
+		filename := query.Get("filename")
+		if file := query.Get("file"); file != "" {
+			filename, _, _ = strings.Cut(file, "$$")
+		}
+		if action != "GetScriptCode" {
+			actions = append(actions, action+" "+query.Get("script_id")+query.Get("title")+" "+filename)
+		}
+
+		switch action {
+		case "GetScriptCode":
+			writeJSON(w, codes[query.Get("script_id")])
+		case "EditScript":
+			writeJSON(w, scripts[query.Get("script_id")])
+		case "CreateScript":
+			writeJSON(w, map[string]any{"id": 5, "title": query.Get("title"), "status": "ACTIVE", "version_number": 1})
+		case "CreateScriptAttachment":
+			writeJSON(w, filename)
+		case "RemoveScriptAttachment":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	handler.HandleFunc("/api/scripts", func(w http.ResponseWriter, r *http.Request) {
+		results := []map[string]any{}
+		for _, id := range []string{"1", "2", "3", "4", "6"} {
+			results = append(results, scripts[id])
+		}
+		writeJSON(w, map[string]any{"count": len(results), "results": results})
+	})
+	handler.HandleFunc("/api/scripts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if strings.HasSuffix(id, ":archive") {
+			archived = append(archived, strings.TrimSuffix(id, ":archive"))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if id == "5" {
+			writeJSON(w, map[string]any{"id": 5, "title": "new", "status": "ACTIVE", "version_number": 1})
+			return
+		}
+		writeJSON(w, scripts[id])
+	})
+	handler.HandleFunc("/api/scripts/2/attachments/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, "dest=/old\n")
+	})
+	handler.HandleFunc("/api/scripts/1/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"id": 8, "filename": "old.txt"}})
+	})
+	handler.HandleFunc("/api/scripts/6/attachments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"id": 10, "filename": "changed.txt"}, {"id": 9, "filename": "same.txt"}})
+	})
+	handler.HandleFunc("/api/scripts/6/attachments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, "contents of "+r.PathValue("id"))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	api, err := client.NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatalf("failed to init client: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"backup.yaml": "id: 2\ntitle: backup\ncode_file: backup.sh\ntime_limit: 600\nattachments: [backup.conf]\n",
+		"backup.sh":   "#!/bin/bash\ntar c /srv /etc\n",
+		"backup.conf": "dest=/backups\n",
+		"hello.yaml":  "title: hello\ncode_file: hello.sh\ninterpreter: /bin/sh\nattachments: []\n",
+		"hello.sh":    "echo hello\n",
+		"new.yml":     "title: new\ncode_file: new.sh\nscript_type: V2\nattachments: [backup.conf]\n",
+		"new.sh":      "#!/bin/sh\necho new\n",
+	})
+
+	manifests, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	plan, err := NewPlan(context.Background(), api.Scripts(), manifests, true)
+	if err != nil {
+		t.Fatalf("NewPlan failed: %v", err)
+	}
+
+	var got []string
+	for _, c := range plan.Changes {
+		got = append(got, string(c.Action)+" "+c.Title())
+	}
+	if want := []string{"edit backup", "edit hello", "create new", "archive stale"}; !slices.Equal(got, want) {
+		t.Fatalf("expected changes %q, got %q", want, got)
+	}
+
+	backup := plan.Changes[0]
+	if backup.Edit.Title != nil || backup.Edit.Code == nil || *backup.Edit.TimeLimit != 600 {
+		t.Errorf("unexpected edit %+v", backup.Edit)
+	}
+	if !slices.Equal(backup.RemoveAttachments, []string{"backup.conf"}) || len(backup.AddAttachments) != 1 {
+		t.Errorf("expected backup.conf to be replaced, got %+v", backup)
+	}
+
+	hello := plan.Changes[1]
+	if hello.Edit != (client.EditScriptParams{}) || !slices.Equal(hello.RemoveAttachments, []string{"old.txt"}) {
+		t.Errorf("expected only old.txt to be removed, got %+v", hello)
+	}
+
+	if err := plan.Apply(context.Background(), api.Scripts(), nil); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	want := []string{
+		"EditScript 2 ",
+		"RemoveScriptAttachment 2 backup.conf",
+		"CreateScriptAttachment 2 backup.conf",
+		"RemoveScriptAttachment 1 old.txt",
+		"CreateScript new ",
+		"CreateScriptAttachment 5 backup.conf",
+	}
+	if !slices.Equal(actions, want) {
+		t.Errorf("expected actions %q, got %q", want, actions)
+	}
+	if !slices.Equal(archived, []string{"3"}) {
+		t.Errorf("expected script 3 to be archived, got %q", archived)
+	}
+
+	t.Run("no prune", func(t *testing.T) {
+		plan, err := NewPlan(context.Background(), api.Scripts(), manifests, false)
+		if err != nil {
+			t.Fatalf("NewPlan failed: %v", err)
+		}
+		if plan.Count(Archive) != 0 {
+			t.Fatalf("expected no archives without prune, got %+v", plan.Changes)
+		}
+	})
+
+	t.Run("V1 attachment contents", func(t *testing.T) {
+		m := &Manifest{
+			Title:       "legacy",
+			Path:        "legacy.yaml",
+			Code:        codes["6"],
+			Attachments: []string{"same.txt", "changed.txt"},
+			Files: []File{
+				{Path: "same.txt", Name: "same.txt", Contents: []byte("contents of 9")},
+				{Path: "changed.txt", Name: "changed.txt", Contents: []byte("new contents")},
+			},
+		}
+		plan, err := NewPlan(context.Background(), api.Scripts(), []*Manifest{m}, false)
+		if err != nil {
+			t.Fatalf("NewPlan failed: %v", err)
+		}
+		if len(plan.Changes) != 1 {
+			t.Fatalf("expected one change, got %+v", plan.Changes)
+		}
+
+		c := plan.Changes[0]
+		if !slices.Equal(c.RemoveAttachments, []string{"changed.txt"}) || len(c.AddAttachments) != 1 || c.AddAttachments[0].Name != "changed.txt" {
+			t.Fatalf("expected only changed.txt to be replaced, got %+v", c)
+		}
+	})
+
+	t.Run("same script by id and title", func(t *testing.T) {
+		byID := &Manifest{ID: 2, Title: "backup", Path: "backup.yaml"}
+		byTitle := &Manifest{Title: "backup", Path: "backup-copy.yaml"}
+		if _, err := NewPlan(context.Background(), api.Scripts(), []*Manifest{byID, byTitle}, false); err == nil || !strings.Contains(err.Error(), "backup.yaml and backup-copy.yaml both describe script 2") {
+			t.Fatalf("expected an error for manifests describing the same script, got %v", err)
+		}
+	})
+
+	t.Run("archived id", func(t *testing.T) {
+		m := &Manifest{ID: 4, Title: "gone", Path: "gone.yaml"}
+		if _, err := NewPlan(context.Background(), api.Scripts(), []*Manifest{m}, false); err == nil || !strings.Contains(err.Error(), "can't be edited") {
+			t.Fatalf("expected an error for an archived script, got %v", err)
+		}
+	})
+}
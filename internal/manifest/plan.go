@@ -0,0 +1,303 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jansdhillon/landscape-go-api-client/client"
+)
+
+// Action is what a change does to a script.
+type Action string
+
+const (
+	Create  Action = "create"
+	Edit    Action = "edit"
+	Archive Action = "archive"
+)
+
+// Change is a change to one script, needed to make it match its manifest.
+type Change struct {
+	Action Action
+	// Manifest is the script's manifest, or nil for archives.
+	Manifest *Manifest
+	// Script is the script as it is on the server, or nil for creates.
+	Script client.Script
+	// Code is the script's current code, for edits.
+	Code string
+	// Edit holds the fields that differ from the manifest, for edits.
+	Edit client.EditScriptParams
+	// RemoveAttachments are the filenames of the attachments to remove,
+	// including those whose contents changed.
+	RemoveAttachments []string
+	// AddAttachments are the files to attach, after removing the old
+	// ones.
+	AddAttachments []File
+}
+
+// Title returns the title of the script the change is to.
+func (c Change) Title() string {
+	if c.Manifest != nil {
+		return c.Manifest.Title
+	}
+	return c.Script.GetTitle()
+}
+
+// Plan is the list of changes that make the scripts on the server match
+// the manifests.
+type Plan struct {
+	Changes []Change
+}
+
+// Count returns the number of changes with the given action.
+func (p *Plan) Count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// NewPlan compares the manifests with the scripts on the server and
+// returns the changes needed to make them match. With prune, active V2
+// scripts that no manifest matches are archived.
+func NewPlan(ctx context.Context, scripts *client.ScriptService, manifests []*Manifest, prune bool) (*Plan, error) {
+	var existing []client.Script
+	for script, err := range scripts.All(ctx, client.ListScriptsParams{}) {
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, script)
+	}
+
+	plan := &Plan{}
+	// matched holds the path of the manifest each script matched, since
+	// manifests matched by ID and by title can still describe the same
+	// script.
+	matched := map[int]string{}
+
+	for _, m := range manifests {
+		script, err := match(m, existing)
+		if err != nil {
+			return nil, err
+		}
+
+		if script == nil {
+			plan.Changes = append(plan.Changes, Change{Action: Create, Manifest: m, AddAttachments: m.Files})
+			continue
+		}
+		if other, ok := matched[script.GetID()]; ok {
+			return nil, fmt.Errorf("%s and %s both describe script %d", other, m.Path, script.GetID())
+		}
+		matched[script.GetID()] = m.Path
+
+		change, err := editChange(ctx, scripts, m, script)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Path, err)
+		}
+		if change != nil {
+			plan.Changes = append(plan.Changes, *change)
+		}
+	}
+
+	if prune {
+		for _, script := range existing {
+			if _, ok := matched[script.GetID()]; !ok && script.GetStatus() == string(client.ACTIVE) {
+				plan.Changes = append(plan.Changes, Change{Action: Archive, Script: script})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// match returns the script the manifest describes, or nil if it doesn't
+// exist yet.
+func match(m *Manifest, scripts []client.Script) (client.Script, error) {
+	if m.ID != 0 {
+		i := slices.IndexFunc(scripts, func(s client.Script) bool { return s.GetID() == m.ID })
+		if i < 0 {
+			return nil, fmt.Errorf("%s: there is no script with id %d", m.Path, m.ID)
+		}
+		if !editable(scripts[i]) {
+			return nil, fmt.Errorf("%s: script %d is %s, so it can't be edited", m.Path, m.ID, scripts[i].GetStatus())
+		}
+		return scripts[i], nil
+	}
+
+	var found client.Script
+	for _, s := range scripts {
+		if s.GetTitle() != m.Title || !editable(s) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%s: scripts %d and %d are both called %q; set id to choose one", m.Path, found.GetID(), s.GetID(), m.Title)
+		}
+		found = s
+	}
+
+	return found, nil
+}
+
+// editable reports whether a script can be edited, which archived and
+// redacted scripts can't.
+func editable(s client.Script) bool {
+	status := s.GetStatus()
+	return status == string(client.V1) || status == string(client.ACTIVE)
+}
+
+// editChange returns the edit that makes script match its manifest, or nil
+// if it already does.
+func editChange(ctx context.Context, scripts *client.ScriptService, m *Manifest, script client.Script) (*Change, error) {
+	code, err := scripts.GetCode(ctx, script.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	change := &Change{Action: Edit, Manifest: m, Script: script, Code: code}
+
+	if script.GetTitle() != m.Title {
+		change.Edit.Title = &m.Title
+	}
+	if code != m.Code {
+		change.Edit.Code = &m.Code
+	}
+	if m.TimeLimit != nil && script.GetTimeLimit() != *m.TimeLimit {
+		change.Edit.TimeLimit = m.TimeLimit
+	}
+	if m.Username != nil && script.GetUsername() != *m.Username {
+		change.Edit.Username = m.Username
+	}
+	if m.AccessGroup != nil && script.GetAccessGroup() != *m.AccessGroup {
+		change.Edit.AccessGroup = m.AccessGroup
+	}
+
+	if m.Attachments != nil {
+		if err := attachmentChanges(ctx, scripts, change, script, m.Files); err != nil {
+			return nil, err
+		}
+	}
+
+	if change.Edit == (client.EditScriptParams{}) && len(change.AddAttachments) == 0 && len(change.RemoveAttachments) == 0 {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// attachmentChanges adds the attachments to remove from and add to script
+// to change, so that it has exactly files. Attachments whose contents
+// changed are replaced.
+func attachmentChanges(ctx context.Context, scripts *client.ScriptService, change *Change, script client.Script, files []File) error {
+	current, err := scripts.ListAttachments(ctx, script.GetID())
+	if err != nil {
+		return err
+	}
+
+	for _, a := range current {
+		i := slices.IndexFunc(files, func(f File) bool { return f.Name == a.Filename })
+		if i < 0 {
+			change.RemoveAttachments = append(change.RemoveAttachments, a.Filename)
+			continue
+		}
+
+		var contents bytes.Buffer
+		if _, err := scripts.DownloadAttachment(ctx, script.GetID(), a.Id, &contents); err != nil {
+			return fmt.Errorf("failed to download attachment %s: %w", a.Filename, err)
+		}
+		if !bytes.Equal(contents.Bytes(), files[i].Contents) {
+			change.RemoveAttachments = append(change.RemoveAttachments, a.Filename)
+			change.AddAttachments = append(change.AddAttachments, files[i])
+		}
+	}
+
+	for _, f := range files {
+		if !slices.ContainsFunc(current, func(a client.ScriptAttachment) bool { return a.Filename == f.Name }) {
+			change.AddAttachments = append(change.AddAttachments, f)
+		}
+	}
+
+	return nil
+}
+
+// Apply makes the changes in order, stopping at the first one that fails.
+// done is called after each change with the resulting script, which is nil
+// for archives.
+func (p *Plan) Apply(ctx context.Context, scripts *client.ScriptService, done func(Change, client.Script)) error {
+	for _, c := range p.Changes {
+		script, err := apply(ctx, scripts, c)
+		if err != nil {
+			return fmt.Errorf("failed to %s script %q: %w", c.Action, c.Title(), err)
+		}
+		if done != nil {
+			done(c, script)
+		}
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, scripts *client.ScriptService, c Change) (client.Script, error) {
+	switch c.Action {
+	case Create:
+		m := c.Manifest
+		params := client.CreateScriptParams{Title: m.Title, Code: m.Code, ScriptType: m.ScriptType}
+		if m.TimeLimit != nil {
+			params.TimeLimit = *m.TimeLimit
+		}
+		if m.Username != nil {
+			params.Username = *m.Username
+		}
+		if m.AccessGroup != nil {
+			params.AccessGroup = *m.AccessGroup
+		}
+
+		script, err := scripts.Create(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return attach(ctx, scripts, script, c)
+
+	case Edit:
+		script := c.Script
+		if c.Edit != (client.EditScriptParams{}) {
+			var err error
+			if script, err = scripts.Edit(ctx, script.GetID(), c.Edit); err != nil {
+				return nil, err
+			}
+		}
+		return attach(ctx, scripts, script, c)
+
+	case Archive:
+		return nil, scripts.Archive(ctx, c.Script.GetID())
+
+	default:
+		return nil, fmt.Errorf("unknown action %q", c.Action)
+	}
+}
+
+// attach removes and adds the change's attachments, and returns the script
+// as it is afterwards.
+func attach(ctx context.Context, scripts *client.ScriptService, script client.Script, c Change) (client.Script, error) {
+	if len(c.RemoveAttachments) == 0 && len(c.AddAttachments) == 0 {
+		return script, nil
+	}
+
+	for _, filename := range c.RemoveAttachments {
+		if err := scripts.RemoveAttachment(ctx, script.GetID(), filename); err != nil {
+			return nil, fmt.Errorf("failed to remove attachment %s: %w", filename, err)
+		}
+	}
+	for _, f := range c.AddAttachments {
+		if _, err := scripts.AddAttachment(ctx, script.GetID(), f.Name, f.Contents); err != nil {
+			return nil, fmt.Errorf("failed to attach %s: %w", f.Path, err)
+		}
+	}
+
+	return scripts.Get(ctx, script.GetID())
+}